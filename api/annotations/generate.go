@@ -0,0 +1,3 @@
+package annotations
+
+//go:generate protoc -I ../../third_party --go_out=paths=source_relative:. zeus/api/annotations.proto
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/JellyTony/zeus/api/annotations"
+	gapi "google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	contextPackage   = protogen.GoImportPath("context")
+	transportPackage = protogen.GoImportPath("github.com/JellyTony/zeus/transport/http")
+	bindingPackage   = protogen.GoImportPath("github.com/go-kratos/kratos/v2/transport/http/binding")
+)
+
+var methodSets = make(map[string]int)
+
+// generateFile generates a _http.pb.go file containing the zeus HTTP handlers and clients.
+func generateFile(gen *protogen.Plugin, file *protogen.File, omitempty bool) *protogen.GeneratedFile {
+	if len(file.Services) == 0 || (omitempty && !hasHTTPRule(file.Services)) {
+		return nil
+	}
+	filename := file.GeneratedFilenamePrefix + "_http.pb.go"
+	g := gen.NewGeneratedFile(filename, file.GoImportPath)
+	g.P("// Code generated by protoc-gen-go-zeus-http. DO NOT EDIT.")
+	g.P("// versions:")
+	g.P(fmt.Sprintf("// - protoc-gen-go-zeus-http %s", release))
+	g.P("// - protoc             ", protocVersion(gen))
+	if file.Proto.GetOptions().GetDeprecated() {
+		g.P("// ", file.Desc.Path(), " is a deprecated file.")
+	} else {
+		g.P("// source: ", file.Desc.Path())
+	}
+	g.P()
+	g.P("package ", file.GoPackageName)
+	g.P()
+	generateFileContent(gen, file, g, omitempty)
+	return g
+}
+
+// generateFileContent generates the zeus HTTP handlers and clients, excluding the package statement.
+func generateFileContent(gen *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile, omitempty bool) {
+	if len(file.Services) == 0 {
+		return
+	}
+	g.P("// This is a compile-time assertion to ensure that this generated file")
+	g.P("// is compatible with the zeus package it is being compiled against.")
+	g.P("var _ = new(", contextPackage.Ident("Context"), ")")
+	g.P("var _ = ", bindingPackage.Ident("EncodeURL"))
+	g.P("const _ = ", transportPackage.Ident("SupportPackageIsVersion1"))
+	g.P()
+
+	for _, service := range file.Services {
+		genService(gen, file, g, service, omitempty)
+	}
+}
+
+func genService(_ *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile, service *protogen.Service, omitempty bool) {
+	// the handlers are numbered per service.
+	methodSets = make(map[string]int)
+	sd := &serviceDesc{
+		ServiceType: service.GoName,
+		ServiceName: string(service.Desc.FullName()),
+		Metadata:    file.Desc.Path(),
+	}
+	for _, method := range service.Methods {
+		if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() {
+			continue
+		}
+		rule, ok := proto.GetExtension(method.Desc.Options(), gapi.E_Http).(*gapi.HttpRule)
+		if rule != nil && ok {
+			sd.Methods = append(sd.Methods, buildHTTPRule(g, method, rule))
+			for _, bind := range rule.AdditionalBindings {
+				sd.Methods = append(sd.Methods, buildHTTPRule(g, method, bind))
+			}
+		} else if !omitempty {
+			path := fmt.Sprintf("/%s/%s", service.Desc.FullName(), method.Desc.Name())
+			md := buildMethodDesc(g, method, http.MethodPost, path, false)
+			md.HasBody, md.WholeBody, md.Body = true, true, "*"
+			sd.Methods = append(sd.Methods, md)
+		}
+	}
+	if len(sd.Methods) != 0 {
+		g.P(sd.execute())
+	}
+}
+
+func hasHTTPRule(services []*protogen.Service) bool {
+	for _, service := range services {
+		for _, method := range service.Methods {
+			if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() {
+				continue
+			}
+			rule, ok := proto.GetExtension(method.Desc.Options(), gapi.E_Http).(*gapi.HttpRule)
+			if rule != nil && ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func buildHTTPRule(g *protogen.GeneratedFile, m *protogen.Method, rule *gapi.HttpRule) *methodDesc {
+	var (
+		path   string
+		method string
+		custom bool
+	)
+	switch pattern := rule.Pattern.(type) {
+	case *gapi.HttpRule_Get:
+		path, method = pattern.Get, http.MethodGet
+	case *gapi.HttpRule_Put:
+		path, method = pattern.Put, http.MethodPut
+	case *gapi.HttpRule_Post:
+		path, method = pattern.Post, http.MethodPost
+	case *gapi.HttpRule_Delete:
+		path, method = pattern.Delete, http.MethodDelete
+	case *gapi.HttpRule_Patch:
+		path, method = pattern.Patch, http.MethodPatch
+	case *gapi.HttpRule_Custom:
+		path, method, custom = pattern.Custom.Path, pattern.Custom.Kind, true
+	}
+	md := buildMethodDesc(g, m, method, path, custom)
+	body := rule.Body
+	md.Body, md.ResponseBodyName = body, rule.ResponseBody
+	switch {
+	case body == "*":
+		md.HasBody, md.WholeBody = true, true
+	case body != "":
+		fd := m.Desc.Input().Fields().ByName(protoreflect.Name(body))
+		if fd == nil {
+			fmt.Fprintf(os.Stderr, "\u001B[31mERROR\u001B[m: The body field %s is not found in %s.\n", body, m.Desc.Input().FullName())
+			os.Exit(2)
+		}
+		md.HasBody, md.BodyField = true, "."+camelCaseVars(body)
+	}
+	if rule.ResponseBody != "" {
+		if m.Desc.Output().Fields().ByName(protoreflect.Name(rule.ResponseBody)) == nil {
+			fmt.Fprintf(os.Stderr, "\u001B[31mERROR\u001B[m: The response body field %s is not found in %s.\n", rule.ResponseBody, m.Desc.Output().FullName())
+			os.Exit(2)
+		}
+		// the response body is decoded as the server encodes it, which is not the Go value of the field.
+		md.ReplyValue = fmt.Sprintf("http.ReplyBody(&out, %q)", rule.ResponseBody)
+	}
+	return md
+}
+
+func buildMethodDesc(g *protogen.GeneratedFile, m *protogen.Method, method, path string, custom bool) *methodDesc {
+	defer func() { methodSets[m.GoName]++ }()
+
+	// the router compiles the path template of the rule, with its custom verb if any.
+	route := path
+	vars := buildPathVars(path)
+	for v, s := range vars {
+		fields := m.Input.Desc.Fields()
+		if s != nil {
+			path = replacePath(v, *s, path)
+		}
+		// the client fills the path from the form encoding of the request, which uses the JSON names.
+		jsonNames := make([]string, 0, strings.Count(v, ".")+1)
+		for _, field := range strings.Split(v, ".") {
+			if strings.TrimSpace(field) == "" {
+				continue
+			}
+			fd := fields.ByName(protoreflect.Name(field))
+			if fd == nil {
+				fmt.Fprintf(os.Stderr, "\u001B[31mERROR\u001B[m: The corresponding field '%s' declaration in message could not be found in '%s'\n", v, path)
+				os.Exit(2)
+			}
+			jsonNames = append(jsonNames, fd.JSONName())
+			if fd.IsMap() {
+				fmt.Fprintf(os.Stderr, "\u001B[31mWARN\u001B[m: The field in path:'%s' shouldn't be a map.\n", v)
+			} else if fd.IsList() {
+				fmt.Fprintf(os.Stderr, "\u001B[31mWARN\u001B[m: The field in path:'%s' shouldn't be a list.\n", v)
+			} else if fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+				fields = fd.Message().Fields()
+			}
+		}
+		path = strings.ReplaceAll(path, "{"+v+"}", "{"+strings.Join(jsonNames, ".")+"}")
+	}
+	comment := m.Comments.Leading.String() + m.Comments.Trailing.String()
+	if comment != "" {
+		comment = "// " + m.GoName + strings.TrimPrefix(strings.TrimSuffix(comment, "\n"), "//")
+	}
+	return &methodDesc{
+		Name:         m.GoName,
+		OriginalName: string(m.Desc.Name()),
+		Num:          methodSets[m.GoName],
+		Request:      g.QualifiedGoIdent(m.Input.GoIdent),
+		Reply:        g.QualifiedGoIdent(m.Output.GoIdent),
+		Comment:      comment,
+		Path:         path,
+		Route:        route,
+		ReplyValue:   "&out",
+		HTTPMethod:   method,
+		Custom:       custom,
+		HasVars:      len(vars) > 0,
+		Middleware:   middlewareHints(m),
+	}
+}
+
+// middlewareHints returns the quoted names of the middleware the routes of the method are registered with.
+func middlewareHints(m *protogen.Method) string {
+	names, _ := proto.GetExtension(m.Desc.Options(), annotations.E_Middleware).([]string)
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, strconv.Quote(name))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// buildPathVars returns the variables of a path template, with their segments
+// template when they have one: {name=messages/*} is name: messages/*.
+func buildPathVars(path string) (res map[string]*string) {
+	if strings.HasSuffix(path, "/") {
+		fmt.Fprintf(os.Stderr, "\u001B[31mWARN\u001B[m: Path %s should not end with \"/\" \n", path)
+	}
+	pattern := regexp.MustCompile(`(?i){([a-z.0-9_\s]*)=?([^{}]*)}`)
+	matches := pattern.FindAllStringSubmatch(path, -1)
+	res = make(map[string]*string, len(matches))
+	for _, m := range matches {
+		name := strings.TrimSpace(m[1])
+		if len(name) > 1 && len(m[2]) > 0 {
+			res[name] = &m[2]
+		} else {
+			res[name] = nil
+		}
+	}
+	return
+}
+
+// replacePath drops the segments template of a variable, as the client
+// fills the whole variable: {name=messages/*} becomes {name}.
+func replacePath(name string, value string, path string) string {
+	pattern := regexp.MustCompile(fmt.Sprintf(`(?i){\s*%s\s*=\s*%s\s*}`, regexp.QuoteMeta(name), regexp.QuoteMeta(value)))
+	return pattern.ReplaceAllLiteralString(path, "{"+name+"}")
+}
+
+func camelCaseVars(s string) string {
+	subs := strings.Split(s, ".")
+	vars := make([]string, 0, len(subs))
+	for _, sub := range subs {
+		vars = append(vars, camelCase(sub))
+	}
+	return strings.Join(vars, ".")
+}
+
+// camelCase returns the CamelCased name.
+// If there is an interior underscore followed by a lower case letter,
+// drop the underscore and convert the letter to upper case.
+// There is a remote possibility of this rewrite causing a name collision,
+// but it's so remote we're prepared to pretend it's nonexistent - since the
+// C++ generator lowercase names, it's extremely unlikely to have two fields
+// with different capitalization.
+// In short, _my_field_name_2 becomes XMyFieldName_2.
+func camelCase(s string) string {
+	if s == "" {
+		return ""
+	}
+	t := make([]byte, 0, 32)
+	i := 0
+	if s[0] == '_' {
+		// Need a capital letter; drop the '_'.
+		t = append(t, 'X')
+		i++
+	}
+	// Invariant: if the next letter is lower case, it must be converted
+	// to upper case.
+	// That is, we process a word at a time, where words are marked by _ or
+	// upper case letter. Digits are treated as words.
+	for ; i < len(s); i++ {
+		c := s[i]
+		if c == '_' && i+1 < len(s) && isASCIILower(s[i+1]) {
+			continue // Skip the underscore in s.
+		}
+		if isASCIIDigit(c) {
+			t = append(t, c)
+			continue
+		}
+		// Assume we have a letter now - if not, it's a bogus identifier.
+		// The next word is a sequence of characters that must start upper case.
+		if isASCIILower(c) {
+			c ^= ' ' // Make it a capital letter.
+		}
+		t = append(t, c) // Guaranteed not lower case.
+		// Accept lower case sequence that follows.
+		for i+1 < len(s) && isASCIILower(s[i+1]) {
+			i++
+			t = append(t, s[i])
+		}
+	}
+	return string(t)
+}
+
+// Is c an ASCII lower-case letter?
+func isASCIILower(c byte) bool {
+	return 'a' <= c && c <= 'z'
+}
+
+// Is c an ASCII digit?
+func isASCIIDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func protocVersion(gen *protogen.Plugin) string {
+	v := gen.Request.GetCompilerVersion()
+	if v == nil {
+		return "(unknown)"
+	}
+	var suffix string
+	if s := v.GetSuffix(); s != "" {
+		suffix = "-" + s
+	}
+	return fmt.Sprintf("v%d.%d.%d%s", v.GetMajor(), v.GetMinor(), v.GetPatch(), suffix)
+}
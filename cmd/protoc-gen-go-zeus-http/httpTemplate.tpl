@@ -0,0 +1,107 @@
+{{$svrType := .ServiceType}}
+{{$svrName := .ServiceName}}
+
+{{- range .MethodSets}}
+const Operation{{$svrType}}{{.OriginalName}} = "/{{$svrName}}/{{.OriginalName}}"
+{{- end}}
+
+type {{.ServiceType}}HTTPServer interface {
+{{- range .MethodSets}}
+	{{- if ne .Comment ""}}
+	{{.Comment}}
+	{{- end}}
+	{{.Name}}(context.Context, *{{.Request}}) (*{{.Reply}}, error)
+{{- end}}
+}
+
+func Register{{.ServiceType}}HTTPServer(s *http.Server, srv {{.ServiceType}}HTTPServer) {
+	r := s.Route("/")
+	{{- range .Methods}}
+	r.{{if .Custom}}Handle("{{.HTTPMethod}}", {{else}}{{.HTTPMethod}}({{end}}"{{.Route}}", _{{$svrType}}_{{.Name}}{{.Num}}_HTTP_Handler(srv){{if .Middleware}}, s.MiddlewareByName({{.Middleware}})...{{end}})
+	r.Describe("{{.HTTPMethod}}", "{{.Route}}", http.RouteInfo{
+		Operation: Operation{{$svrType}}{{.OriginalName}},
+		Request: (*{{.Request}})(nil),
+		Reply: (*{{.Reply}})(nil),
+		{{- if .Body}}
+		Body: "{{.Body}}",
+		{{- end}}
+		{{- if .ResponseBodyName}}
+		ResponseBody: "{{.ResponseBodyName}}",
+		{{- end}}
+	})
+	{{- end}}
+}
+
+{{range .Methods}}
+func _{{$svrType}}_{{.Name}}{{.Num}}_HTTP_Handler(srv {{$svrType}}HTTPServer) func(ctx http.Context) error {
+	return func(ctx http.Context) error {
+		var in {{.Request}}
+		{{- if not .WholeBody}}
+		if err := ctx.BindQuery(&in); err != nil {
+			return err
+		}
+		{{- end}}
+		{{- if .HasBody}}
+		if err := ctx.Bind(&in{{.BodyField}}); err != nil {
+			return err
+		}
+		{{- end}}
+		{{- if .HasVars}}
+		if err := ctx.BindVars(&in); err != nil {
+			return err
+		}
+		{{- end}}
+		http.SetOperation(ctx, Operation{{$svrType}}{{.OriginalName}})
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.{{.Name}}(ctx, req.(*{{.Request}}))
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*{{.Reply}})
+		{{- if .ResponseBodyName}}
+		body, err := http.ResponseBody(ctx.Request(), reply, "{{.ResponseBodyName}}")
+		if err != nil {
+			return err
+		}
+		return ctx.Result(200, body)
+		{{- else}}
+		return ctx.Result(200, reply)
+		{{- end}}
+	}
+}
+{{end}}
+
+type {{.ServiceType}}HTTPClient interface {
+{{- range .MethodSets}}
+	{{.Name}}(ctx context.Context, req *{{.Request}}, opts ...http.CallOption) (rsp *{{.Reply}}, err error)
+{{- end}}
+}
+
+type {{.ServiceType}}HTTPClientImpl struct {
+	cc *http.Client
+}
+
+func New{{.ServiceType}}HTTPClient(client *http.Client) {{.ServiceType}}HTTPClient {
+	return &{{.ServiceType}}HTTPClientImpl{client}
+}
+
+{{range .MethodSets}}
+func (c *{{$svrType}}HTTPClientImpl) {{.Name}}(ctx context.Context, in *{{.Request}}, opts ...http.CallOption) (*{{.Reply}}, error) {
+	var out {{.Reply}}
+	pattern := "{{.Path}}"
+	path := binding.EncodeURL(pattern, in, {{not .HasBody}})
+	opts = append(opts, http.Operation(Operation{{$svrType}}{{.OriginalName}}))
+	opts = append(opts, http.PathTemplate(pattern))
+	{{- if .HasBody}}
+	err := c.cc.Invoke(ctx, "{{.HTTPMethod}}", path, in{{.BodyField}}, {{.ReplyValue}}, opts...)
+	{{- else}}
+	err := c.cc.Invoke(ctx, "{{.HTTPMethod}}", path, nil, {{.ReplyValue}}, opts...)
+	{{- end}}
+	if err != nil {
+		return nil, err
+	}
+	return &out, err
+}
+{{end}}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	zeusapi "github.com/JellyTony/zeus/api/annotations"
+	"github.com/JellyTony/zeus/internal/testdata/greeter"
+	zhttp "github.com/JellyTony/zeus/transport/http"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestBuildPathVars(t *testing.T) {
+	segments := "messages/*"
+	tests := []struct {
+		path string
+		want map[string]*string
+	}{
+		{"/helloworld/{name}", map[string]*string{"name": nil}},
+		{"/v1/{name=messages/*}", map[string]*string{"name": &segments}},
+		{"/v1/users/{user.id}/{name}", map[string]*string{"user.id": nil, "name": nil}},
+		{"/v1/users", map[string]*string{}},
+	}
+	for _, test := range tests {
+		if got := buildPathVars(test.path); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: expected %v got %v", test.path, test.want, got)
+		}
+	}
+}
+
+func TestReplacePath(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		path  string
+		want  string
+	}{
+		{"name", "messages/*", "/v1/{name=messages/*}", "/v1/{name}"},
+		{"path", "**", "/v1/files/{path=**}", "/v1/files/{path}"},
+		{"name", "messages/*", "/v1/{ name = messages/* }:cancel", "/v1/{name}:cancel"},
+		{"name", "*", "/v1/{id}", "/v1/{id}"},
+	}
+	for _, test := range tests {
+		if got := replacePath(test.name, test.value, test.path); got != test.want {
+			t.Errorf("expected %s got %s", test.want, got)
+		}
+	}
+}
+
+func TestCamelCaseVars(t *testing.T) {
+	tests := map[string]string{
+		"user_id":        "UserId",
+		"profile":        "Profile",
+		"user.user_name": "User.UserName",
+		"_my_field_2":    "XMyField_2",
+	}
+	for in, want := range tests {
+		if got := camelCaseVars(in); got != want {
+			t.Errorf("expected %s got %s", want, got)
+		}
+	}
+}
+
+var update = flag.Bool("update", false, "update the generated code of the testdata")
+
+// TestGenerateFile checks the generated code of the testdata is up to date,
+// run it with -update to regenerate it.
+func TestGenerateFile(t *testing.T) {
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"greeter.proto"},
+		Parameter:      proto.String("paths=source_relative"),
+		ProtoFile: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+			protodesc.ToFileDescriptorProto(annotations.File_google_api_http_proto),
+			protodesc.ToFileDescriptorProto(annotations.File_google_api_annotations_proto),
+			protodesc.ToFileDescriptorProto(zeusapi.File_zeus_api_annotations_proto),
+			protodesc.ToFileDescriptorProto(greeter.File_greeter_proto),
+		},
+		CompilerVersion: &pluginpb.Version{Major: proto.Int32(3), Minor: proto.Int32(17), Patch: proto.Int32(3)},
+	}
+	gen, err := protogen.Options{}.New(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range gen.Files {
+		if f.Generate {
+			generateFile(gen, f, true)
+		}
+	}
+	res := gen.Response()
+	if res.Error != nil {
+		t.Fatal(res.GetError())
+	}
+	if len(res.File) != 1 || res.File[0].GetName() != "greeter_http.pb.go" {
+		t.Fatalf("expected %s got %v", "greeter_http.pb.go", res.File)
+	}
+	golden := "../../internal/testdata/greeter/greeter_http.pb.go"
+	if *update {
+		if err = os.WriteFile(golden, []byte(res.File[0].GetContent()), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.File[0].GetContent() != string(want) {
+		t.Errorf("the generated code of the testdata is out of date, got:\n%s", res.File[0].GetContent())
+	}
+}
+
+type greeterService struct{}
+
+func (greeterService) SayHello(_ context.Context, in *greeter.HelloRequest) (*greeter.HelloReply, error) {
+	greeting := in.Greeting
+	if greeting == "" {
+		greeting = "hello"
+	}
+	return &greeter.HelloReply{Message: greeting + " " + in.Name}, nil
+}
+
+func (greeterService) UpdateProfile(_ context.Context, in *greeter.UpdateProfileRequest) (*greeter.Profile, error) {
+	return &greeter.Profile{UserId: in.UserId, Nickname: in.GetProfile().GetNickname()}, nil
+}
+
+func (greeterService) ListFiles(_ context.Context, in *greeter.ListFilesRequest) (*greeter.ListFilesReply, error) {
+	return &greeter.ListFilesReply{Files: []*greeter.File{{Name: in.Path + "/a", SizeBytes: 1}, {Name: in.Path + "/b", SizeBytes: 2}}}, nil
+}
+
+func (greeterService) CancelFile(_ context.Context, in *greeter.CancelFileRequest) (*greeter.File, error) {
+	return &greeter.File{Name: in.Name}, nil
+}
+
+func TestGeneratedCode(t *testing.T) {
+	var calls []string
+	hint := func(name string) middleware.Middleware {
+		return func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				calls = append(calls, name)
+				return handler(ctx, req)
+			}
+		}
+	}
+	srv := zhttp.NewServer(zhttp.NamedMiddleware("auth", hint("auth")), zhttp.NamedMiddleware("audit", hint("audit")))
+	greeter.RegisterGreeterHTTPServer(srv, greeterService{})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	client, err := zhttp.NewClient(context.Background(), zhttp.WithEndpoint(strings.TrimPrefix(ts.URL, "http://")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := greeter.NewGreeterHTTPClient(client)
+	ctx := context.Background()
+
+	reply, err := cc.SayHello(ctx, &greeter.HelloRequest{Name: "zeus", Greeting: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Message != "hi zeus" {
+		t.Errorf("expected %s got %s", "hi zeus", reply.Message)
+	}
+	if len(calls) != 0 {
+		t.Errorf("expected no middleware hints got %v", calls)
+	}
+
+	profile, err := cc.UpdateProfile(ctx, &greeter.UpdateProfileRequest{UserId: "1", Profile: &greeter.Profile{Nickname: "zeus"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.UserId != "1" || profile.Nickname != "zeus" {
+		t.Errorf("unexpected profile %v", profile)
+	}
+	if !reflect.DeepEqual(calls, []string{"auth", "audit"}) {
+		t.Errorf("expected %v got %v", []string{"auth", "audit"}, calls)
+	}
+
+	files, err := cc.ListFiles(ctx, &greeter.ListFilesRequest{Path: "docs/api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files.Files) != 2 || files.Files[0].Name != "docs/api/a" || files.Files[1].SizeBytes != 2 {
+		t.Errorf("unexpected files %v", files.Files)
+	}
+
+	// the response body field is encoded with the JSON names, as RegisterService encodes it.
+	body, err := http.Get(ts.URL + "/v1/files/docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(body.Body)
+	body.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"name":"docs/a","sizeBytes":"1"},{"name":"docs/b","sizeBytes":"2"}]`
+	if got := strings.Join(strings.Fields(string(data)), ""); got != want {
+		t.Errorf("expected %s got %s", want, got)
+	}
+
+	file, err := cc.CancelFile(ctx, &greeter.CancelFileRequest{Name: "report"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Name != "report" {
+		t.Errorf("expected %s got %s", "report", file.Name)
+	}
+
+	// the additional binding takes the request from the body.
+	res, err := http.Post(ts.URL+"/v1/greeter/zeus", "application/json", strings.NewReader(`{"greeting":"hey"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	out := new(greeter.HelloReply)
+	if err = zhttp.DefaultRequestDecoder(&http.Request{Header: res.Header, Body: res.Body}, out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Message != "hey zeus" {
+		t.Errorf("expected %s got %s", "hey zeus", out.Message)
+	}
+}
+
+func TestGeneratedCodeMissingMiddleware(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for the unregistered middleware hints")
+		}
+	}()
+	greeter.RegisterGreeterHTTPServer(zhttp.NewServer(), greeterService{})
+}
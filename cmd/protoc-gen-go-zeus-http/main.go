@@ -0,0 +1,40 @@
+// protoc-gen-go-zeus-http is a plugin for the Google protocol buffer compiler to generate
+// HTTP handlers and clients of the zeus transport from the google.api.http annotations.
+//
+// It is used with protoc as the other Go plugins:
+//
+//	protoc -I . -I ./third_party --go_out=paths=source_relative:. --go-zeus-http_out=paths=source_relative:. service.proto
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+var (
+	showVersion = flag.Bool("version", false, "print the version and exit")
+	omitempty   = flag.Bool("omitempty", true, "omit the methods without google.api.http annotations")
+)
+
+func main() {
+	flag.Parse()
+	if *showVersion {
+		fmt.Printf("protoc-gen-go-zeus-http %v\n", release)
+		return
+	}
+	protogen.Options{
+		ParamFunc: flag.CommandLine.Set,
+	}.Run(func(gen *protogen.Plugin) error {
+		gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+		for _, f := range gen.Files {
+			if !f.Generate {
+				continue
+			}
+			generateFile(gen, f, *omitempty)
+		}
+		return nil
+	})
+}
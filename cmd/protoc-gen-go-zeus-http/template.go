@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"strings"
+	"text/template"
+)
+
+//go:embed httpTemplate.tpl
+var httpTemplate string
+
+type serviceDesc struct {
+	ServiceType string // Greeter
+	ServiceName string // helloworld.Greeter
+	Metadata    string // api/helloworld/helloworld.proto
+	Methods     []*methodDesc
+	MethodSets  map[string]*methodDesc
+}
+
+type methodDesc struct {
+	// method
+	Name         string
+	OriginalName string // the method name in the proto file
+	Num          int
+	Request      string
+	Reply        string
+	Comment      string
+	// http_rule
+	Path             string // the path template used by the client
+	Route            string // the path template of the route registered by the server
+	HTTPMethod       string
+	Custom           bool
+	HasVars          bool
+	HasBody          bool
+	WholeBody        bool
+	Body             string // the body of the http rule
+	BodyField        string
+	ResponseBodyName string // the response body of the http rule
+	ReplyValue       string // the value the client decodes the response body into
+	Middleware       string // the quoted names of the middleware hints
+}
+
+func (s *serviceDesc) execute() string {
+	s.MethodSets = make(map[string]*methodDesc)
+	for _, m := range s.Methods {
+		if _, ok := s.MethodSets[m.Name]; !ok {
+			s.MethodSets[m.Name] = m
+		}
+	}
+	buf := new(bytes.Buffer)
+	tmpl, err := template.New("http").Parse(strings.TrimSpace(httpTemplate))
+	if err != nil {
+		panic(err)
+	}
+	if err := tmpl.Execute(buf, s); err != nil {
+		panic(err)
+	}
+	return strings.Trim(buf.String(), "\r\n")
+}
@@ -0,0 +1,4 @@
+package main
+
+// release is the current protoc-gen-go-zeus-http version.
+const release = "v0.1.0"
@@ -0,0 +1,197 @@
+// Package breaker provides an adaptive circuit breaker, it implements the client
+// side throttling of the Google SRE book: the requests are rejected locally with
+// probability max(0, (requests - K*accepts) / (requests + 1)) over a rolling window.
+package breaker
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrNotAllowed is returned when the request is rejected by the breaker.
+var ErrNotAllowed = errors.New("circuitbreaker: not allowed for circuit open")
+
+const (
+	defaultSuccess = 0.6
+	defaultWindow  = 3 * time.Second
+	defaultBucket  = 10
+)
+
+// Option is breaker option.
+type Option func(*Breaker)
+
+// WithSuccess with the success ratio the backend is expected to keep, default 0.6.
+// The lower it is, the more failures are tolerated before requests are rejected.
+// A ratio out of (0, 1] is replaced by the default.
+func WithSuccess(s float64) Option {
+	return func(b *Breaker) {
+		b.success = s
+	}
+}
+
+// WithRequest with the minimum number of requests in the window before any is rejected, default 100.
+func WithRequest(r int64) Option {
+	return func(b *Breaker) {
+		b.request = r
+	}
+}
+
+// WithWindow with the length of the rolling window, default 3s.
+// A non-positive window is replaced by the default.
+func WithWindow(d time.Duration) Option {
+	return func(b *Breaker) {
+		b.window = d
+	}
+}
+
+// WithBucket with the number of buckets of the rolling window, default 10.
+// A non-positive number is replaced by the default, a number larger than
+// the window in nanoseconds by the window.
+func WithBucket(n int) Option {
+	return func(b *Breaker) {
+		b.bucket = n
+	}
+}
+
+// Breaker is an adaptive circuit breaker.
+type Breaker struct {
+	success float64
+	k       float64
+	request int64
+	window  time.Duration
+	bucket  int
+	stat    *rollingCounter
+
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// New returns a breaker.
+func New(opts ...Option) *Breaker {
+	b := &Breaker{
+		success: defaultSuccess,
+		request: 100,
+		window:  defaultWindow,
+		bucket:  defaultBucket,
+		r:       rand.New(rand.NewSource(time.Now().UnixNano())), //nolint:gosec
+	}
+	for _, o := range opts {
+		o(b)
+	}
+	// the negated comparison also rejects NaN.
+	if !(b.success > 0 && b.success <= 1) {
+		b.success = defaultSuccess
+	}
+	if b.window <= 0 {
+		b.window = defaultWindow
+	}
+	if b.bucket <= 0 {
+		b.bucket = defaultBucket
+	}
+	b.k = 1 / b.success
+	b.stat = newRollingCounter(b.window, b.bucket)
+	return b
+}
+
+// Allow returns ErrNotAllowed when the request should be rejected.
+func (b *Breaker) Allow() error {
+	accepts, total := b.stat.summary()
+	requests := b.k * float64(accepts)
+	if total < b.request || float64(total) < requests {
+		return nil
+	}
+	dr := (float64(total) - requests) / float64(total+1)
+	if dr <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	drop := b.r.Float64() < dr
+	b.mu.Unlock()
+	if drop {
+		return ErrNotAllowed
+	}
+	return nil
+}
+
+// Open reports whether the breaker rejects any request at the moment.
+func (b *Breaker) Open() bool {
+	accepts, total := b.stat.summary()
+	return total >= b.request && float64(total) > b.k*float64(accepts)
+}
+
+// MarkSuccess records a successful request.
+func (b *Breaker) MarkSuccess() {
+	b.stat.add(1)
+}
+
+// MarkFailed records a failed or rejected request.
+func (b *Breaker) MarkFailed() {
+	b.stat.add(0)
+}
+
+type bucket struct {
+	sum   int64
+	count int64
+}
+
+// rollingCounter counts the values added within the window, which is split into
+// buckets expiring one by one as time goes on.
+type rollingCounter struct {
+	mu      sync.Mutex
+	width   time.Duration
+	buckets []bucket
+	offset  int
+	last    time.Time
+}
+
+func newRollingCounter(window time.Duration, n int) *rollingCounter {
+	if window <= 0 {
+		window = 1
+	}
+	if n <= 0 {
+		n = 1
+	}
+	// the buckets are at least a nanosecond wide.
+	if time.Duration(n) > window {
+		n = int(window)
+	}
+	return &rollingCounter{
+		width:   window / time.Duration(n),
+		buckets: make([]bucket, n),
+		last:    time.Now(),
+	}
+}
+
+// advance expires the buckets older than the window.
+func (c *rollingCounter) advance(now time.Time) {
+	span := int(now.Sub(c.last) / c.width)
+	if span <= 0 {
+		return
+	}
+	for i := 0; i < span && i < len(c.buckets); i++ {
+		c.offset = (c.offset + 1) % len(c.buckets)
+		c.buckets[c.offset] = bucket{}
+	}
+	c.last = c.last.Add(time.Duration(span) * c.width)
+}
+
+func (c *rollingCounter) add(v int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.advance(time.Now())
+	c.buckets[c.offset].sum += v
+	c.buckets[c.offset].count++
+}
+
+func (c *rollingCounter) summary() (sum int64, count int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.advance(time.Now())
+	for _, b := range c.buckets {
+		sum += b.sum
+		count += b.count
+	}
+	return sum, count
+}
@@ -0,0 +1,136 @@
+package breaker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreakerAllow(t *testing.T) {
+	b := New(WithRequest(10))
+	for i := 0; i < 100; i++ {
+		b.MarkSuccess()
+	}
+	for i := 0; i < 100; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("expected healthy breaker to allow got %v", err)
+		}
+	}
+	if b.Open() {
+		t.Error("expected breaker to be closed")
+	}
+
+	b = New(WithRequest(10))
+	for i := 0; i < 1000; i++ {
+		b.MarkFailed()
+	}
+	if !b.Open() {
+		t.Error("expected breaker to be open")
+	}
+	var rejected int
+	for i := 0; i < 100; i++ {
+		if b.Allow() == ErrNotAllowed {
+			rejected++
+		}
+	}
+	if rejected < 90 {
+		t.Errorf("expected most requests to be rejected got %d", rejected)
+	}
+}
+
+func TestBreakerMinRequest(t *testing.T) {
+	b := New(WithRequest(100))
+	for i := 0; i < 99; i++ {
+		b.MarkFailed()
+	}
+	for i := 0; i < 100; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("expected breaker to allow below the minimum requests got %v", err)
+		}
+	}
+}
+
+func TestBreakerSuccess(t *testing.T) {
+	// half of the requests fail, which is tolerated with a success ratio of 0.5.
+	b := New(WithRequest(10), WithSuccess(0.5))
+	for i := 0; i < 100; i++ {
+		b.MarkSuccess()
+		b.MarkFailed()
+	}
+	if b.Open() {
+		t.Error("expected breaker to be closed")
+	}
+	b = New(WithRequest(10), WithSuccess(0.9))
+	for i := 0; i < 100; i++ {
+		b.MarkSuccess()
+		b.MarkFailed()
+	}
+	if !b.Open() {
+		t.Error("expected breaker to be open")
+	}
+}
+
+func TestBreakerWindow(t *testing.T) {
+	b := New(WithRequest(10), WithWindow(100*time.Millisecond), WithBucket(2))
+	for i := 0; i < 100; i++ {
+		b.MarkFailed()
+	}
+	if !b.Open() {
+		t.Error("expected breaker to be open")
+	}
+	time.Sleep(150 * time.Millisecond)
+	if b.Open() {
+		t.Error("expected breaker to be closed once the window has passed")
+	}
+}
+
+func TestRollingCounter(t *testing.T) {
+	c := newRollingCounter(100*time.Millisecond, 2)
+	c.add(1)
+	c.add(0)
+	if sum, count := c.summary(); sum != 1 || count != 2 {
+		t.Errorf("expected 1/2 got %d/%d", sum, count)
+	}
+	time.Sleep(60 * time.Millisecond)
+	c.add(1)
+	if sum, count := c.summary(); sum != 2 || count != 3 {
+		t.Errorf("expected 2/3 got %d/%d", sum, count)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if sum, count := c.summary(); sum != 1 || count != 1 {
+		t.Errorf("expected 1/1 got %d/%d", sum, count)
+	}
+}
+
+func TestBreakerOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		k       float64
+		window  time.Duration
+		buckets int
+	}{
+		{"default", nil, 1 / 0.6, 3 * time.Second, 10},
+		{"zero success", []Option{WithSuccess(0)}, 1 / 0.6, 3 * time.Second, 10},
+		{"negative success", []Option{WithSuccess(-1)}, 1 / 0.6, 3 * time.Second, 10},
+		{"success above one", []Option{WithSuccess(2)}, 1 / 0.6, 3 * time.Second, 10},
+		{"success of one", []Option{WithSuccess(1)}, 1, 3 * time.Second, 10},
+		{"zero window", []Option{WithWindow(0)}, 1 / 0.6, 3 * time.Second, 10},
+		{"negative window", []Option{WithWindow(-time.Second)}, 1 / 0.6, 3 * time.Second, 10},
+		{"zero bucket", []Option{WithBucket(0)}, 1 / 0.6, 3 * time.Second, 10},
+		{"window shorter than the buckets", []Option{WithWindow(5), WithBucket(10)}, 1 / 0.6, 5, 5},
+	}
+	for _, test := range tests {
+		b := New(append(test.opts, WithRequest(1))...)
+		if b.k != test.k || b.window != test.window || len(b.stat.buckets) != test.buckets {
+			t.Errorf("%s: expected %v %v %d got %v %v %d", test.name, test.k, test.window, test.buckets, b.k, b.window, len(b.stat.buckets))
+		}
+		if b.stat.width <= 0 {
+			t.Errorf("%s: expected a positive bucket width got %v", test.name, b.stat.width)
+		}
+		// the statistics are recorded and read without a division by zero.
+		b.MarkSuccess()
+		b.MarkFailed()
+		_ = b.Allow()
+		_ = b.Open()
+	}
+}
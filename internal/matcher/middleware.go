@@ -12,6 +12,7 @@ type Matcher interface {
 	Use(ms ...middleware.Middleware)
 	Add(selector string, ms ...middleware.Middleware)
 	Match(operation string) []middleware.Middleware
+	Selectors(operation string) []string
 }
 
 // New new a middleware matcher.
@@ -60,3 +61,22 @@ func (m *matcher) Match(operation string) []middleware.Middleware {
 	}
 	return ms
 }
+
+// Selectors returns the selectors whose middleware Match returns for the operation,
+// the default middleware is not reported.
+func (m *matcher) Selectors(operation string) []string {
+	if _, ok := m.matchs[operation]; ok {
+		for _, prefix := range m.prefix {
+			if prefix == operation {
+				return []string{prefix + "*"}
+			}
+		}
+		return []string{operation}
+	}
+	for _, prefix := range m.prefix {
+		if strings.HasPrefix(operation, prefix) {
+			return []string{prefix + "*"}
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package matcher
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	"github.com/go-kratos/kratos/v2/middleware"
@@ -60,3 +61,27 @@ func TestMatcher(t *testing.T) {
 		t.Fatal("not equal")
 	}
 }
+
+func TestSelectors(t *testing.T) {
+	m := New()
+	m.Use(logging("logging"))
+	m.Add("/foo/*", logging("foo/*"))
+	m.Add("/foo/bar/*", logging("foo/bar/*"))
+	m.Add("/foo/bar", logging("foo/bar"))
+
+	tests := []struct {
+		operation string
+		want      []string
+	}{
+		{"/", nil},
+		{"/foo/xxx", []string{"/foo/*"}},
+		{"/foo/bar", []string{"/foo/bar"}},
+		{"/foo/bar/", []string{"/foo/bar/*"}},
+		{"/foo/bar/x", []string{"/foo/bar/*"}},
+	}
+	for _, test := range tests {
+		if got := m.Selectors(test.operation); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: expected %v got %v", test.operation, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package pathtemplate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Template is a compiled HTTP path template.
+// It translates google.api.http style templates such as:
+//   - /helloworld/{name}
+//   - /v1/{name=messages/*}
+//   - /files/{path=**}
+//   - /v1/{name=operations/*}:cancel
+//
+// into gin route patterns, and rebuilds the template variables
+// from the params captured by gin. The custom verb is matched by
+// the last segment of the pattern, the routes with a verb share
+// the pattern of the route without it.
+type Template struct {
+	raw       string
+	pattern   string
+	path      string
+	verb      string
+	verbParam string
+	verbPath  string
+	vars      []variable
+	templated bool
+}
+
+type variable struct {
+	name  string
+	parts []part
+}
+
+type part struct {
+	literal  string
+	param    string
+	catchAll bool
+}
+
+// Compile parses a path template into a Template.
+func Compile(tpl string) (*Template, error) {
+	t := &Template{raw: tpl}
+	if !strings.HasPrefix(tpl, "/") {
+		return nil, fmt.Errorf("path template %q must start with '/'", tpl)
+	}
+	var (
+		segments []string
+		anon     int
+		rest     = tpl[1:]
+	)
+	if i := verbIndex(tpl); i >= 0 {
+		t.verb, rest = tpl[i+1:], tpl[1:i]
+		if t.verb == "" || strings.ContainsAny(t.verb, "/{}:*") {
+			return nil, fmt.Errorf("path template %q has invalid verb %q", tpl, t.verb)
+		}
+	}
+	for len(rest) > 0 || len(segments) == 0 {
+		var seg string
+		if strings.HasPrefix(rest, "{") {
+			end := strings.Index(rest, "}")
+			if end < 0 {
+				return nil, fmt.Errorf("path template %q has unclosed variable", tpl)
+			}
+			if end+1 < len(rest) && rest[end+1] != '/' {
+				return nil, fmt.Errorf("path template %q has unsupported suffix %q", tpl, rest[end+1:])
+			}
+			v, pattern, err := compileVariable(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("path template %q: %v", tpl, err)
+			}
+			t.vars = append(t.vars, v)
+			t.templated = true
+			seg, rest = pattern, rest[end+1:]
+		} else {
+			end := strings.Index(rest, "/")
+			if end < 0 {
+				end = len(rest)
+			}
+			seg, rest = rest[:end], rest[end:]
+			switch {
+			case strings.ContainsAny(seg, "{}"):
+				return nil, fmt.Errorf("path template %q has invalid segment %q", tpl, seg)
+			case seg == "*":
+				seg = ":" + anonymous(anon)
+				anon++
+				t.templated = true
+			case seg == "**":
+				seg = "*" + anonymous(anon)
+				anon++
+				t.templated = true
+			case strings.HasPrefix(seg, ":"):
+				t.vars = append(t.vars, variable{name: seg[1:], parts: []part{{param: seg[1:]}}})
+			case strings.HasPrefix(seg, "*"):
+				t.vars = append(t.vars, variable{name: seg[1:], parts: []part{{param: seg[1:]}}})
+			}
+		}
+		segments = append(segments, seg)
+		if strings.HasPrefix(rest, "/") {
+			rest = rest[1:]
+			if rest == "" {
+				segments = append(segments, "")
+			}
+		}
+		if rest == "" {
+			break
+		}
+	}
+	t.pattern = "/" + strings.Join(segments, "/")
+	if i := strings.Index(t.pattern, "*"); i >= 0 && strings.Contains(t.pattern[i:], "/") {
+		return nil, fmt.Errorf("path template %q: '**' must be the last segment", tpl)
+	}
+	t.path = t.pattern
+	if t.verb != "" {
+		last := segments[len(segments)-1]
+		// the segment of a variable may span several segments of the pattern.
+		last = last[strings.LastIndex(last, "/")+1:]
+		switch {
+		case last == "":
+			return nil, fmt.Errorf("path template %q has a verb after a trailing slash", tpl)
+		case last[0] == ':' || last[0] == '*':
+			// the verb is captured by the param of the last segment.
+			t.verbParam = last[1:]
+		default:
+			// the verb is captured by an anonymous param following the literal.
+			t.pattern += ":" + anonymous(anon)
+			t.verbPath = "/" + last + ":" + t.verb
+		}
+		t.path += ":" + t.verb
+		t.templated = true
+	}
+	return t, nil
+}
+
+// verbIndex returns the index of the colon of the custom verb of a template, or -1.
+// The colon starting a gin param segment such as /users/:name is not a verb.
+func verbIndex(tpl string) int {
+	i := strings.LastIndexAny(tpl, "/}")
+	if i < 0 {
+		return -1
+	}
+	j := strings.IndexByte(tpl[i+1:], ':')
+	if j < 0 || (j == 0 && tpl[i] == '/') {
+		return -1
+	}
+	return i + 1 + j
+}
+
+// MustCompile is like Compile but panics if the template cannot be parsed.
+func MustCompile(tpl string) *Template {
+	t, err := Compile(tpl)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func compileVariable(s string) (variable, string, error) {
+	name, tpl := s, "*"
+	if i := strings.Index(s, "="); i >= 0 {
+		name, tpl = s[:i], s[i+1:]
+	}
+	if name == "" || strings.ContainsAny(name, "/:*") {
+		return variable{}, "", fmt.Errorf("invalid variable name %q", name)
+	}
+	if tpl == "" {
+		return variable{}, "", fmt.Errorf("variable %q has empty template", name)
+	}
+	v := variable{name: name}
+	segs := strings.Split(tpl, "/")
+	patterns := make([]string, 0, len(segs))
+	wildcards := strings.Count(tpl, "*") - strings.Count(tpl, "**")
+	for i, seg := range segs {
+		param := name
+		if len(segs) > 1 || wildcards > 1 {
+			param = name + "$" + strconv.Itoa(i)
+		}
+		switch {
+		case seg == "*":
+			v.parts = append(v.parts, part{param: param})
+			patterns = append(patterns, ":"+param)
+		case seg == "**":
+			if i != len(segs)-1 {
+				return variable{}, "", fmt.Errorf("variable %q: '**' must be the last segment", name)
+			}
+			v.parts = append(v.parts, part{param: param, catchAll: true})
+			patterns = append(patterns, "*"+param)
+		case seg == "" || strings.ContainsAny(seg, "*:{}"):
+			return variable{}, "", fmt.Errorf("variable %q has invalid segment %q", name, seg)
+		default:
+			v.parts = append(v.parts, part{literal: seg})
+			patterns = append(patterns, seg)
+		}
+	}
+	return v, strings.Join(patterns, "/"), nil
+}
+
+func anonymous(i int) string {
+	return "$" + strconv.Itoa(i)
+}
+
+// Template returns the raw path template.
+func (t *Template) Template() string {
+	return t.raw
+}
+
+// Pattern returns the gin route pattern.
+func (t *Template) Pattern() string {
+	return t.pattern
+}
+
+// Path returns the gin route pattern with the custom verb, such as /v1/:name:cancel,
+// it is the pattern of the templates without a verb.
+func (t *Template) Path() string {
+	return t.path
+}
+
+// Verb returns the custom verb of the template, or "".
+func (t *Template) Verb() string {
+	return t.verb
+}
+
+// MatchVerb reports whether the request path matched by the pattern ends with the
+// custom verb of the template.
+func (t *Template) MatchVerb(path string) bool {
+	switch {
+	case t.verb == "":
+		return false
+	case t.verbPath != "":
+		return strings.HasSuffix(path, t.verbPath)
+	}
+	return strings.HasSuffix(path, ":"+t.verb) && !strings.HasSuffix(path, "/:"+t.verb)
+}
+
+// Templated reports whether the template uses variables or wildcards
+// that gin cannot capture on its own.
+func (t *Template) Templated() bool {
+	return t.templated
+}
+
+// Params rebuilds the template variables from the params captured by gin.
+func (t *Template) Params(ps gin.Params) gin.Params {
+	params := make(gin.Params, 0, len(t.vars))
+	for _, v := range t.vars {
+		var sb strings.Builder
+		for i, p := range v.parts {
+			if i > 0 {
+				sb.WriteByte('/')
+			}
+			if p.literal != "" {
+				sb.WriteString(p.literal)
+				continue
+			}
+			value := ps.ByName(p.param)
+			if p.catchAll {
+				value = strings.TrimPrefix(value, "/")
+			}
+			if t.verbParam != "" && p.param == t.verbParam {
+				value = strings.TrimSuffix(value, ":"+t.verb)
+			}
+			sb.WriteString(value)
+		}
+		params = append(params, gin.Param{Key: v.name, Value: sb.String()})
+	}
+	return params
+}
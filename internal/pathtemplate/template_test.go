@@ -0,0 +1,168 @@
+package pathtemplate
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		tpl       string
+		pattern   string
+		templated bool
+	}{
+		{"/", "/", false},
+		{"/index/", "/index/", false},
+		{"/users/:name", "/users/:name", false},
+		{"/static/*filepath", "/static/*filepath", false},
+		{"/helloworld/{name}", "/helloworld/:name", true},
+		{"/helloworld/{name}/", "/helloworld/:name/", true},
+		{"/v1/{simple.component}", "/v1/:simple.component", true},
+		{"/v1/{name=*}", "/v1/:name", true},
+		{"/files/{path=**}", "/files/*path", true},
+		{"/v1/{name=messages/*}", "/v1/messages/:name$1", true},
+		{"/v1/{name=shelves/*/books/*}/read", "/v1/shelves/:name$1/books/:name$3/read", true},
+		{"/v1/{name=messages/**}", "/v1/messages/*name$1", true},
+		{"/v1/*/foo", "/v1/:$0/foo", true},
+		{"/v1/{name}:cancel", "/v1/:name", true},
+		{"/v1/{name=operations/*}:cancel", "/v1/operations/:name$1", true},
+		{"/v1/messages:batchGet", "/v1/messages:$0", true},
+		{"/v1/*:batchGet", "/v1/:$0", true},
+		{"/files/{path=**}:download", "/files/*path", true},
+	}
+	for _, test := range tests {
+		t.Run(test.tpl, func(t *testing.T) {
+			tpl, err := Compile(test.tpl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tpl.Pattern() != test.pattern {
+				t.Errorf("expected %s got %s", test.pattern, tpl.Pattern())
+			}
+			if tpl.Templated() != test.templated {
+				t.Errorf("expected %v got %v", test.templated, tpl.Templated())
+			}
+			if tpl.Template() != test.tpl {
+				t.Errorf("expected %s got %s", test.tpl, tpl.Template())
+			}
+		})
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	tests := []string{
+		"",
+		"helloworld/{name}",
+		"/helloworld/{name",
+		"/helloworld/{}",
+		"/helloworld/{=messages/*}",
+		"/helloworld/{name=}",
+		"/helloworld/{name}:",
+		"/helloworld/{name}:verb/foo",
+		"/helloworld/{name}:v:erb",
+		"/helloworld/prefix{name}",
+		"/helloworld/{name=**}/foo",
+		"/helloworld/{name=**/foo}",
+		"/helloworld/{name=messages//*}",
+	}
+	for _, test := range tests {
+		if _, err := Compile(test); err == nil {
+			t.Errorf("expected error for %q", test)
+		}
+	}
+}
+
+func TestParams(t *testing.T) {
+	tests := []struct {
+		tpl    string
+		params gin.Params
+		want   gin.Params
+	}{
+		{
+			"/helloworld/{name}",
+			gin.Params{{Key: "name", Value: "kratos"}},
+			gin.Params{{Key: "name", Value: "kratos"}},
+		},
+		{
+			"/files/{path=**}",
+			gin.Params{{Key: "path", Value: "/a/b/c.txt"}},
+			gin.Params{{Key: "path", Value: "a/b/c.txt"}},
+		},
+		{
+			"/v1/{name=shelves/*/books/*}",
+			gin.Params{{Key: "name$1", Value: "1"}, {Key: "name$3", Value: "2"}},
+			gin.Params{{Key: "name", Value: "shelves/1/books/2"}},
+		},
+		{
+			"/v1/:id/{name=messages/**}",
+			gin.Params{{Key: "id", Value: "1"}, {Key: "name$1", Value: "/a/b"}},
+			gin.Params{{Key: "id", Value: "1"}, {Key: "name", Value: "messages/a/b"}},
+		},
+		{
+			"/v1/*/{name}",
+			gin.Params{{Key: "$0", Value: "any"}, {Key: "name", Value: "foo"}},
+			gin.Params{{Key: "name", Value: "foo"}},
+		},
+		{
+			"/v1/{name=operations/*}:cancel",
+			gin.Params{{Key: "name$1", Value: "1:cancel"}},
+			gin.Params{{Key: "name", Value: "operations/1"}},
+		},
+		{
+			"/files/{path=**}:download",
+			gin.Params{{Key: "path", Value: "/a/b:c.txt:download"}},
+			gin.Params{{Key: "path", Value: "a/b:c.txt"}},
+		},
+		{
+			"/v1/messages:batchGet",
+			gin.Params{{Key: "$0", Value: ":batchGet"}},
+			gin.Params{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.tpl, func(t *testing.T) {
+			got := MustCompile(test.tpl).Params(test.params)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestVerb(t *testing.T) {
+	tests := []struct {
+		tpl   string
+		path  string
+		verb  string
+		match map[string]bool
+	}{
+		{"/v1/{name}", "/v1/:name", "", map[string]bool{"/v1/foo": false, "/v1/foo:cancel": false}},
+		{"/v1/{name}:cancel", "/v1/:name:cancel", "cancel", map[string]bool{
+			"/v1/foo:cancel": true,
+			"/v1/:cancel":    false,
+			"/v1/foo":        false,
+			"/v1/foo:get":    false,
+		}},
+		{"/v1/messages:batchGet", "/v1/messages:batchGet", "batchGet", map[string]bool{
+			"/v1/messages:batchGet":    true,
+			"/v1/messagesfoo:batchGet": false,
+			"/v1/messages":             false,
+		}},
+	}
+	for _, test := range tests {
+		tpl := MustCompile(test.tpl)
+		if tpl.Path() != test.path {
+			t.Errorf("%s: expected %s got %s", test.tpl, test.path, tpl.Path())
+		}
+		if tpl.Verb() != test.verb {
+			t.Errorf("%s: expected %s got %s", test.tpl, test.verb, tpl.Verb())
+		}
+		for path, want := range test.match {
+			if got := tpl.MatchVerb(path); got != want {
+				t.Errorf("%s %s: expected %v got %v", test.tpl, path, want, got)
+			}
+		}
+	}
+}
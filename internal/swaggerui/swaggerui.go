@@ -0,0 +1,14 @@
+// Package swaggerui embeds the bundle script and the stylesheet of the Swagger UI 5.17.14
+// distribution, gzipped. Swagger UI is licensed under the Apache License 2.0, see the
+// LICENSE file.
+package swaggerui
+
+import "embed"
+
+// Version is the version of the embedded Swagger UI.
+const Version = "5.17.14"
+
+// FS holds swagger-ui-bundle.js.gz and swagger-ui.css.gz.
+//
+//go:embed *.gz
+var FS embed.FS
@@ -0,0 +1,3 @@
+package greeter
+
+//go:generate protoc -I . -I ../../../third_party --go_out=paths=source_relative:. --go-zeus-http_out=paths=source_relative:. ./greeter.proto
@@ -0,0 +1,171 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// AdminAddress with the address of the admin server, which serves pprof, expvar,
+// the registered routes and the runtime config. It is isolated from the business
+// routes, so it should listen on an address not exposed to the public ingress.
+func AdminAddress(addr string) ServerOption {
+	return func(s *Server) {
+		s.adminAddress = addr
+	}
+}
+
+// AdminListener with the admin server listener.
+func AdminListener(lis net.Listener) ServerOption {
+	return func(s *Server) {
+		s.adminLis = lis
+	}
+}
+
+type adminRoute struct {
+	Method    string   `json:"method"`
+	Path      string   `json:"path"`
+	Template  string   `json:"template,omitempty"`
+	Operation string   `json:"operation,omitempty"`
+	Selectors []string `json:"selectors,omitempty"`
+}
+
+type adminConfig struct {
+	Network           string   `json:"network"`
+	Address           string   `json:"address"`
+	Endpoints         []string `json:"endpoints"`
+	TLS               bool     `json:"tls"`
+	H2C               bool     `json:"h2c"`
+	Timeout           string   `json:"timeout"`
+	ReadTimeout       string   `json:"read_timeout"`
+	ReadHeaderTimeout string   `json:"read_header_timeout"`
+	WriteTimeout      string   `json:"write_timeout"`
+	IdleTimeout       string   `json:"idle_timeout"`
+	MaxHeaderBytes    int      `json:"max_header_bytes"`
+	MaxBodySize       int64    `json:"max_body_size"`
+	PreStopDelay      string   `json:"pre_stop_delay"`
+	InFlight          int64    `json:"in_flight"`
+	GoVersion         string   `json:"go_version"`
+	GOMAXPROCS        int      `json:"gomaxprocs"`
+	NumGoroutine      int      `json:"num_goroutine"`
+}
+
+func (s *Server) adminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/routes", s.adminRoutes)
+	mux.HandleFunc("/debug/config", s.adminConfig)
+	return mux
+}
+
+func (s *Server) adminRoutes(w http.ResponseWriter, r *http.Request) {
+	var routes []adminRoute
+	_ = s.WalkRoute(func(info RouteInfo) error {
+		route := adminRoute{Method: info.Method, Path: info.Path, Operation: info.Operation}
+		template := info.Path
+		if tpl, ok := s.templates[info.Path]; ok {
+			route.Template = tpl.Template()
+			template = tpl.Template()
+		}
+		// the middleware selected by the template runs for every request of the route,
+		// the middleware selected by the operation runs once it is set by the handler.
+		route.Selectors = s.middleware.Selectors(template)
+		if info.Operation != "" && info.Operation != template {
+			for _, selector := range s.middleware.Selectors(info.Operation) {
+				if len(route.Selectors) == 0 || route.Selectors[0] != selector {
+					route.Selectors = append(route.Selectors, selector)
+				}
+			}
+		}
+		routes = append(routes, route)
+		return nil
+	})
+	writeAdminJSON(w, routes)
+}
+
+func (s *Server) adminConfig(w http.ResponseWriter, r *http.Request) {
+	c := adminConfig{
+		Network:           s.network,
+		Address:           s.address,
+		TLS:               s.tlsConf != nil,
+		H2C:               s.h2c,
+		Timeout:           s.timeout.String(),
+		ReadTimeout:       s.readTimeout.String(),
+		ReadHeaderTimeout: s.readHeaderTimeout.String(),
+		WriteTimeout:      s.writeTimeout.String(),
+		IdleTimeout:       s.idleTimeout.String(),
+		MaxHeaderBytes:    s.maxHeaderBytes,
+		MaxBodySize:       s.maxBodySize,
+		PreStopDelay:      s.preStopDelay.String(),
+		InFlight:          s.InFlight(),
+		GoVersion:         runtime.Version(),
+		GOMAXPROCS:        runtime.GOMAXPROCS(0),
+		NumGoroutine:      runtime.NumGoroutine(),
+	}
+	if endpoints, err := s.Endpoints(); err == nil {
+		for _, e := range endpoints {
+			c.Endpoints = append(c.Endpoints, e.String())
+		}
+	}
+	writeAdminJSON(w, c)
+}
+
+func writeAdminJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func (s *Server) newAdmin() {
+	if s.adminLis == nil && s.adminAddress == "" {
+		return
+	}
+	s.admin = &http.Server{
+		Handler:           s.adminHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+func (s *Server) startAdmin(ctx context.Context) error {
+	if s.admin == nil {
+		return nil
+	}
+	if s.adminLis == nil {
+		lis, err := net.Listen("tcp", s.adminAddress)
+		if err != nil {
+			return err
+		}
+		s.adminLis = lis
+	}
+	s.admin.BaseContext = func(net.Listener) context.Context {
+		return ctx
+	}
+	log.Infof("[HTTP] admin server listening on: %s", s.adminLis.Addr().String())
+	go func() {
+		if err := s.admin.Serve(s.adminLis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("[HTTP] admin server serve error: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (s *Server) stopAdmin(ctx context.Context) error {
+	if s.admin == nil {
+		return nil
+	}
+	return s.admin.Shutdown(ctx)
+}
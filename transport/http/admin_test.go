@@ -0,0 +1,125 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAdminRoutes(t *testing.T) {
+	srv := NewServer(Middleware(customMiddleware()))
+	srv.Use("/helloworld/*", customMiddleware())
+	srv.Route("/").GET("/helloworld/{name}", func(ctx Context) error { return nil })
+	srv.Route("/").GET("/index", func(ctx Context) error { return nil })
+	srv.Use("/helloworld.Greeter/*", customMiddleware())
+	r := srv.Route("/")
+	r.GET("/v1/greeter/{name}", func(ctx Context) error { return nil })
+	r.Describe(http.MethodGet, "/v1/greeter/{name}", RouteInfo{Operation: "/helloworld.Greeter/SayHello"})
+	r.GET("/helloworld/{name}/greeting", func(ctx Context) error { return nil })
+	r.Describe(http.MethodGet, "/helloworld/{name}/greeting", RouteInfo{Operation: "/helloworld.Greeter/SayHello"})
+
+	res := httptest.NewRecorder()
+	srv.adminHandler().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/debug/routes", nil))
+	var routes []adminRoute
+	if err := json.Unmarshal(res.Body.Bytes(), &routes); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]adminRoute{
+		"/helloworld/:name": {
+			Method:    http.MethodGet,
+			Path:      "/helloworld/:name",
+			Template:  "/helloworld/{name}",
+			Selectors: []string{"/helloworld/*"},
+		},
+		"/index": {
+			Method:   http.MethodGet,
+			Path:     "/index",
+			Template: "/index",
+		},
+		"/v1/greeter/:name": {
+			Method:    http.MethodGet,
+			Path:      "/v1/greeter/:name",
+			Template:  "/v1/greeter/{name}",
+			Operation: "/helloworld.Greeter/SayHello",
+			Selectors: []string{"/helloworld.Greeter/*"},
+		},
+		"/helloworld/:name/greeting": {
+			Method:    http.MethodGet,
+			Path:      "/helloworld/:name/greeting",
+			Template:  "/helloworld/{name}/greeting",
+			Operation: "/helloworld.Greeter/SayHello",
+			Selectors: []string{"/helloworld/*", "/helloworld.Greeter/*"},
+		},
+	}
+	for _, route := range routes {
+		if w, ok := want[route.Path]; ok && !reflect.DeepEqual(w, route) {
+			t.Errorf("expected %+v got %+v", w, route)
+		}
+		delete(want, route.Path)
+	}
+	if len(want) > 0 {
+		t.Errorf("expected routes %v", want)
+	}
+}
+
+func TestAdminServer(t *testing.T) {
+	ctx := context.Background()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := NewServer(AdminListener(lis), MaxBodySize(1024))
+	if _, err = srv.Endpoint(); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+
+	base := "http://" + lis.Addr().String()
+	resp, err := http.Get(base + "/debug/config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c adminConfig
+	err = json.NewDecoder(resp.Body).Decode(&c)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.MaxBodySize != 1024 || len(c.Endpoints) != 1 {
+		t.Errorf("unexpected config %+v", c)
+	}
+	for _, path := range []string{"/debug/pprof/", "/debug/vars"} {
+		resp, err = http.Get(base + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: expected %d got %d", path, http.StatusOK, resp.StatusCode)
+		}
+	}
+
+	// the admin routes are not served by the business server.
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+	if res.Code != http.StatusNotFound {
+		t.Errorf("expected %d got %d", http.StatusNotFound, res.Code)
+	}
+
+	if err = srv.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = http.Get(base + "/debug/vars"); err == nil {
+		t.Error("expected admin server to be stopped")
+	}
+}
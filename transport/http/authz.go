@@ -0,0 +1,65 @@
+package http
+
+import (
+	"context"
+
+	"github.com/JellyTony/zeus/internal/matcher"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const (
+	reasonPeerUnauthenticated = "PEER_UNAUTHENTICATED"
+	reasonPeerForbidden       = "PEER_FORBIDDEN"
+)
+
+// Authorize returns a middleware that only allows the peers whose identity is
+// allowed for the operation. The rules map operation selectors to identities:
+//   - '/*'
+//   - '/helloworld.v1.Greeter/*'
+//   - '/helloworld.v1.Greeter/SayHello'
+//
+// An identity matches the SPIFFE ID, a URI or DNS subject alternative name, or
+// the common name of the peer certificate, and "*" matches any verified peer.
+// Operations not matched by any selector are allowed.
+func Authorize(rules map[string][]string) middleware.Middleware {
+	m := matcher.New()
+	for selector, identities := range rules {
+		m.Add(selector, allowPeers(identities))
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				if next := m.Match(tr.Operation()); len(next) > 0 {
+					return middleware.Chain(next...)(handler)(ctx, req)
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+func allowPeers(identities []string) middleware.Middleware {
+	allowed := make(map[string]struct{}, len(identities))
+	for _, id := range identities {
+		allowed[id] = struct{}{}
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			p, ok := PeerFromContext(ctx)
+			if !ok {
+				return nil, errors.Unauthorized(reasonPeerUnauthenticated, "peer certificate is required")
+			}
+			if _, ok := allowed["*"]; ok {
+				return handler(ctx, req)
+			}
+			for _, id := range p.Identities() {
+				if _, ok := allowed[id]; ok {
+					return handler(ctx, req)
+				}
+			}
+			return nil, errors.Forbidden(reasonPeerForbidden, "peer is not allowed")
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func TestAuthorize(t *testing.T) {
+	m := Authorize(map[string][]string{
+		"/helloworld.Greeter/*":        {"spiffe://cluster.local/ns/default/sa/client"},
+		"/helloworld.Greeter/SayHello": {"*"},
+		"/admin/*":                     {"admin", "ops.example.com"},
+	})
+	h := m(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	client := &Peer{
+		CommonName: "client",
+		URIs:       []string{"spiffe://cluster.local/ns/default/sa/client"},
+		SPIFFEID:   "spiffe://cluster.local/ns/default/sa/client",
+	}
+	admin := &Peer{CommonName: "admin"}
+	ops := &Peer{CommonName: "ops", DNSNames: []string{"ops.example.com"}}
+
+	tests := []struct {
+		operation string
+		peer      *Peer
+		code      int
+		reason    string
+	}{
+		{"/helloworld.Greeter/SayHello", nil, 401, reasonPeerUnauthenticated},
+		{"/helloworld.Greeter/SayHello", admin, 200, ""},
+		{"/helloworld.Greeter/SayHelloStream", client, 200, ""},
+		{"/helloworld.Greeter/SayHelloStream", admin, 403, reasonPeerForbidden},
+		{"/helloworld.Greeter/SayHelloStream", nil, 401, reasonPeerUnauthenticated},
+		{"/admin/users", admin, 200, ""},
+		{"/admin/users", ops, 200, ""},
+		{"/admin/users", client, 403, reasonPeerForbidden},
+		{"/healthz", nil, 200, ""},
+	}
+	for _, test := range tests {
+		ctx := transport.NewServerContext(context.Background(), &Transport{operation: test.operation, peer: test.peer})
+		_, err := h(ctx, nil)
+		if code := errors.Code(err); code != test.code {
+			t.Errorf("%s %v: expected %d got %d", test.operation, test.peer, test.code, code)
+		}
+		if reason := errors.Reason(err); reason != test.reason {
+			t.Errorf("%s %v: expected %s got %s", test.operation, test.peer, test.reason, reason)
+		}
+	}
+}
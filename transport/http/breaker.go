@@ -0,0 +1,157 @@
+package http
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/JellyTony/zeus/internal/breaker"
+	"github.com/JellyTony/zeus/internal/group"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const reasonCircuitBreakerOpen = "CIRCUIT_BREAKER_OPEN"
+
+// ErrCircuitBreakerOpen is returned when every node of the call is rejected by the circuit breaker.
+var ErrCircuitBreakerOpen = errors.ServiceUnavailable(reasonCircuitBreakerOpen, "request rejected by the circuit breaker")
+
+// CircuitBreakerOption is a circuit breaker option.
+type CircuitBreakerOption func(*CircuitBreaker)
+
+// BreakerSuccess with the success ratio a node is expected to keep, default 0.6.
+// A ratio out of (0, 1] is replaced by the default.
+func BreakerSuccess(s float64) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.opts = append(cb.opts, breaker.WithSuccess(s))
+	}
+}
+
+// BreakerRequest with the minimum number of requests in the window before any is rejected, default 100.
+func BreakerRequest(r int64) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.opts = append(cb.opts, breaker.WithRequest(r))
+	}
+}
+
+// BreakerWindow with the length of the rolling window of the statistics, default 3s.
+// A non-positive window is replaced by the default.
+func BreakerWindow(d time.Duration) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.opts = append(cb.opts, breaker.WithWindow(d))
+	}
+}
+
+// BreakerBucket with the number of buckets of the rolling window, default 10.
+// A non-positive number is replaced by the default.
+func BreakerBucket(n int) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.opts = append(cb.opts, breaker.WithBucket(n))
+	}
+}
+
+// CircuitBreaker is an adaptive circuit breaker of the client calls, keyed by the node
+// address and the operation. Its node filter excludes the nodes rejected by their breaker,
+// and the client records the result of each attempt against the node it was sent to.
+type CircuitBreaker struct {
+	opts  []breaker.Option
+	group *group.Group
+}
+
+// NewCircuitBreaker returns a circuit breaker.
+func NewCircuitBreaker(opts ...CircuitBreakerOption) *CircuitBreaker {
+	cb := &CircuitBreaker{}
+	for _, o := range opts {
+		o(cb)
+	}
+	cb.group = group.NewGroup(func() interface{} {
+		return breaker.New(cb.opts...)
+	})
+	return cb
+}
+
+func (cb *CircuitBreaker) get(node, operation string) *breaker.Breaker {
+	return cb.group.Get(node + " " + operation).(*breaker.Breaker)
+}
+
+// Open reports whether the breaker of the node and operation is open.
+func (cb *CircuitBreaker) Open(node, operation string) bool {
+	return cb.get(node, operation).Open()
+}
+
+type breakerStateKey struct{}
+
+// breakerState records whether the node filter has rejected any node of a call.
+type breakerState struct {
+	rejected int32
+}
+
+// Middleware returns a client middleware returning ErrCircuitBreakerOpen when no node
+// is left by the node filter.
+func (cb *CircuitBreaker) Middleware() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if _, ok := transport.FromClientContext(ctx); !ok {
+				return handler(ctx, req)
+			}
+			state := new(breakerState)
+			reply, err := handler(context.WithValue(ctx, breakerStateKey{}, state), req)
+			if err != nil && atomic.LoadInt32(&state.rejected) > 0 && errors.Reason(err) == reasonNodeNotFound {
+				return nil, ErrCircuitBreakerOpen
+			}
+			return reply, err
+		}
+	}
+}
+
+// mark records the result of an attempt against the node it was sent to, so that
+// each retried or hedged attempt counts for its own node. The canceled attempts,
+// such as the losers of a hedged call, are not recorded.
+func (cb *CircuitBreaker) mark(ctx context.Context, node string, err error) {
+	tr, ok := transport.FromClientContext(ctx)
+	if !ok || errors.Is(err, context.Canceled) {
+		return
+	}
+	b := cb.get(node, tr.Operation())
+	if err != nil && (errors.IsInternalServer(err) || errors.IsServiceUnavailable(err) || errors.IsGatewayTimeout(err)) {
+		b.MarkFailed()
+	} else {
+		b.MarkSuccess()
+	}
+}
+
+// NodeFilter returns a node filter excluding the nodes whose breaker rejects the operation.
+func (cb *CircuitBreaker) NodeFilter() selector.NodeFilter {
+	return func(ctx context.Context, nodes []selector.Node) []selector.Node {
+		tr, ok := transport.FromClientContext(ctx)
+		if !ok {
+			return nodes
+		}
+		filtered := make([]selector.Node, 0, len(nodes))
+		for _, n := range nodes {
+			b := cb.get(n.Address(), tr.Operation())
+			if err := b.Allow(); err != nil {
+				// NOTE: keep counting the rejected requests to let the drop ratio higher.
+				b.MarkFailed()
+				continue
+			}
+			filtered = append(filtered, n)
+		}
+		if len(filtered) < len(nodes) {
+			if state, ok := ctx.Value(breakerStateKey{}).(*breakerState); ok {
+				atomic.StoreInt32(&state.rejected, 1)
+			}
+		}
+		return filtered
+	}
+}
+
+// WithCircuitBreaker with the client circuit breaker, it installs both the
+// middleware, outermost, and the node filter of the breaker.
+func WithCircuitBreaker(cb *CircuitBreaker) ClientOption {
+	return func(o *clientOptions) {
+		o.breaker = cb
+	}
+}
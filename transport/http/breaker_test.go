@@ -0,0 +1,143 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestCircuitBreakerOpen(t *testing.T) {
+	srv := newTestUpstream(t, 0, -1, http.StatusServiceUnavailable, "")
+	host := strings.TrimPrefix(srv.URL, "http://")
+	cb := NewCircuitBreaker(BreakerRequest(10))
+	client, err := NewClient(context.Background(), WithEndpoint(host), WithCircuitBreaker(cb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rejected int
+	for i := 0; i < 200; i++ {
+		err := client.Invoke(context.Background(), http.MethodGet, "/users", nil, &User{})
+		if code := errors.Code(err); code != http.StatusServiceUnavailable {
+			t.Fatalf("expected %d got %d", http.StatusServiceUnavailable, code)
+		}
+		if errors.Reason(err) == reasonCircuitBreakerOpen {
+			rejected++
+		}
+	}
+	if rejected == 0 {
+		t.Error("expected requests to be rejected by the circuit breaker")
+	}
+	if n := srv.Calls(); int(n)+rejected != 200 {
+		t.Errorf("expected %d calls got %d", 200-rejected, n)
+	}
+	if !cb.Open(host, "/users") {
+		t.Error("expected the circuit breaker to be open")
+	}
+	if cb.Open(host, "/other") {
+		t.Error("expected the circuit breaker of another operation to be closed")
+	}
+}
+
+func TestCircuitBreakerOptions(t *testing.T) {
+	// the invalid options are replaced by the defaults instead of dividing by zero.
+	cb := NewCircuitBreaker(BreakerWindow(0), BreakerSuccess(0), BreakerBucket(0), BreakerRequest(1))
+	for i := 0; i < 10; i++ {
+		cb.get("127.0.0.1:8000", "/users").MarkFailed()
+	}
+	if !cb.Open("127.0.0.1:8000", "/users") {
+		t.Error("expected the circuit breaker to be open")
+	}
+}
+
+func TestCircuitBreakerClientError(t *testing.T) {
+	srv := newTestUpstream(t, 0, -1, http.StatusNotFound, "")
+	host := strings.TrimPrefix(srv.URL, "http://")
+	cb := NewCircuitBreaker(BreakerRequest(10))
+	client, err := NewClient(context.Background(), WithEndpoint(host), WithCircuitBreaker(cb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		err := client.Invoke(context.Background(), http.MethodGet, "/users", nil, &User{})
+		if code := errors.Code(err); code != http.StatusNotFound {
+			t.Fatalf("expected %d got %d", http.StatusNotFound, code)
+		}
+	}
+	if n := srv.Calls(); n != 100 {
+		t.Errorf("expected %d calls got %d", 100, n)
+	}
+	if cb.Open(host, "/users") {
+		t.Error("expected the circuit breaker to be closed")
+	}
+}
+
+func TestCircuitBreakerNodeFilter(t *testing.T) {
+	bad := newTestUpstream(t, 0, -1, http.StatusInternalServerError, "")
+	good := newTestUpstream(t, 0, 0, 0, "")
+	cb := NewCircuitBreaker(BreakerRequest(10))
+	client, err := NewClient(context.Background(),
+		WithEndpoint("discovery:///zeus"),
+		WithDiscovery(&mockDiscovery{endpoints: []string{bad.URL, good.URL}}),
+		WithBlock(),
+		WithCircuitBreaker(cb),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var failed int
+	for i := 0; i < 400; i++ {
+		if err := client.Invoke(context.Background(), http.MethodGet, "/users", nil, &User{}); err != nil {
+			failed++
+		}
+	}
+	if n := bad.Calls(); int(n) != failed {
+		t.Errorf("expected %d failures got %d", n, failed)
+	}
+	// the failing node is excluded once its breaker opens.
+	if bad, good := bad.Calls(), good.Calls(); bad*2 > good {
+		t.Errorf("expected the failing node to be excluded got %d/%d", bad, good)
+	}
+	if !cb.Open(strings.TrimPrefix(bad.URL, "http://"), "/users") {
+		t.Error("expected the circuit breaker of the failing node to be open")
+	}
+	if cb.Open(strings.TrimPrefix(good.URL, "http://"), "/users") {
+		t.Error("expected the circuit breaker of the healthy node to be closed")
+	}
+}
+
+func TestCircuitBreakerRetry(t *testing.T) {
+	bad := newTestUpstream(t, 0, -1, http.StatusServiceUnavailable, "")
+	good := newTestUpstream(t, 0, 0, 0, "")
+	cb := NewCircuitBreaker(BreakerRequest(10))
+	client, err := NewClient(context.Background(),
+		WithEndpoint("discovery:///zeus"),
+		WithDiscovery(&mockDiscovery{endpoints: []string{bad.URL, good.URL}}),
+		WithBlock(),
+		WithCircuitBreaker(cb),
+		WithRetry(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	for i := 0; i < 200; i++ {
+		if err := client.Invoke(context.Background(), http.MethodGet, "/users", nil, &User{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the failed attempts count for the failing node even though the calls succeed.
+	if !cb.Open(strings.TrimPrefix(bad.URL, "http://"), "/users") {
+		t.Error("expected the circuit breaker of the failing node to be open")
+	}
+	if cb.Open(strings.TrimPrefix(good.URL, "http://"), "/users") {
+		t.Error("expected the circuit breaker of the healthy node to be closed")
+	}
+	if bad, good := bad.Calls(), good.Calls(); bad*2 > good {
+		t.Errorf("expected the failing node to be excluded got %d/%d", bad, good)
+	}
+}
@@ -20,6 +20,8 @@ type callInfo struct {
 	contentType  string
 	operation    string
 	pathTemplate string
+	retry        *RetryPolicy
+	hedging      *HedgingPolicy
 }
 
 // EmptyCallOption does not alter the Call configuration.
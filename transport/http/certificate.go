@@ -0,0 +1,148 @@
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const defaultReloadInterval = 30 * time.Second
+
+// CertReloaderOption is a certificate reloader option.
+type CertReloaderOption func(*CertReloader)
+
+// ReloadInterval with the interval at which the certificate files are checked for changes.
+func ReloadInterval(interval time.Duration) CertReloaderOption {
+	return func(r *CertReloader) {
+		r.interval = interval
+	}
+}
+
+// CertReloader serves a certificate key pair loaded from files,
+// and reloads it whenever the files change, without restarting
+// the server or client using it.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	version string
+
+	done chan struct{}
+	once sync.Once
+}
+
+// NewCertReloader loads the certificate key pair and starts watching the files for changes.
+func NewCertReloader(certFile, keyFile string, opts ...CertReloaderOption) (*CertReloader, error) {
+	r := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: defaultReloadInterval,
+		done:     make(chan struct{}),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	go r.watch()
+	return r, nil
+}
+
+// Reload loads the certificate key pair if the files have changed.
+func (r *CertReloader) Reload() error {
+	version, err := r.fileVersion()
+	if err != nil {
+		return err
+	}
+	r.mu.RLock()
+	changed := version != r.version
+	r.mu.RUnlock()
+	if !changed {
+		return nil
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.cert = &cert
+	r.version = version
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *CertReloader) fileVersion() (string, error) {
+	var version string
+	for _, name := range []string{r.certFile, r.keyFile} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return "", err
+		}
+		version += fi.ModTime().String() + "/" + strconv.FormatInt(fi.Size(), 10) + ";"
+	}
+	return version, nil
+}
+
+func (r *CertReloader) watch() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			if err := r.Reload(); err != nil {
+				log.Errorf("[HTTP] failed to reload certificate %s: %v", r.certFile, err)
+			}
+		}
+	}
+}
+
+// Certificate returns the current certificate.
+func (r *CertReloader) Certificate() *tls.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert
+}
+
+// NotAfter returns the expiry of the current certificate.
+func (r *CertReloader) NotAfter() time.Time {
+	return r.Certificate().Leaf.NotAfter
+}
+
+// GetCertificate returns the current certificate, it can be used as tls.Config.GetCertificate.
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if cert := r.Certificate(); cert != nil {
+		return cert, nil
+	}
+	return nil, errors.New("certificate not loaded")
+}
+
+// GetClientCertificate returns the current certificate, it can be used as tls.Config.GetClientCertificate.
+func (r *CertReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	if cert := r.Certificate(); cert != nil {
+		return cert, nil
+	}
+	return nil, errors.New("certificate not loaded")
+}
+
+// Close stops watching the certificate files.
+func (r *CertReloader) Close() error {
+	r.once.Do(func() {
+		close(r.done)
+	})
+	return nil
+}
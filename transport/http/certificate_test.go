@@ -0,0 +1,195 @@
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestCert(t *testing.T, dir string, notAfter time.Time, mtime time.Time) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(notAfter.Unix()),
+		Subject:      pkix.Name{CommonName: "zeus"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile = filepath.Join(dir, "tls.crt")
+	keyFile = filepath.Join(dir, "tls.key")
+	if err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{certFile, keyFile} {
+		if err = os.Chtimes(name, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return certFile, keyFile
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().Truncate(time.Second)
+	first := now.Add(24 * time.Hour)
+	certFile, keyFile := writeTestCert(t, dir, first, now)
+
+	r, err := NewCertReloader(certFile, keyFile, ReloadInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if !r.NotAfter().Equal(first) {
+		t.Errorf("expected %v got %v", first, r.NotAfter())
+	}
+	cert, err := r.GetClientCertificate(nil)
+	if err != nil || cert != r.Certificate() {
+		t.Errorf("expected %v got %v %v", r.Certificate(), cert, err)
+	}
+
+	second := now.Add(48 * time.Hour)
+	writeTestCert(t, dir, second, now.Add(time.Second))
+	time.Sleep(100 * time.Millisecond)
+	if !r.NotAfter().Equal(second) {
+		t.Errorf("expected %v got %v", second, r.NotAfter())
+	}
+
+	// a broken key pair keeps the current certificate.
+	if err = os.WriteFile(keyFile, []byte("broken"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Reload(); err == nil {
+		t.Error("expected reload error")
+	}
+	if !r.NotAfter().Equal(second) {
+		t.Errorf("expected %v got %v", second, r.NotAfter())
+	}
+
+	if _, err = NewCertReloader(filepath.Join(dir, "missing.crt"), keyFile); err == nil {
+		t.Error("expected error for missing certificate")
+	}
+}
+
+func TestServerCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().Truncate(time.Second)
+	certFile, keyFile := writeTestCert(t, dir, now.Add(24*time.Hour), now)
+	r, err := NewCertReloader(certFile, keyFile, ReloadInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	ctx := context.Background()
+	srv := NewServer(TLSCertReloader(r))
+	srv.Route("/").GET("/index", func(ctx Context) error {
+		return ctx.String(200, "ok")
+	})
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Scheme != "https" {
+		t.Errorf("expected %s got %s", "https", e.Scheme)
+	}
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	defer func() { _ = srv.Stop(ctx) }()
+	time.Sleep(time.Second)
+
+	notAfter := func() time.Time {
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+			DisableKeepAlives: true,
+		}}
+		resp, err := client.Get(e.String() + "/index")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		return resp.TLS.PeerCertificates[0].NotAfter
+	}
+	if v := notAfter(); !v.Equal(now.Add(24 * time.Hour)) {
+		t.Errorf("expected %v got %v", now.Add(24*time.Hour), v)
+	}
+	writeTestCert(t, dir, now.Add(48*time.Hour), now.Add(time.Second))
+	time.Sleep(100 * time.Millisecond)
+	if v := notAfter(); !v.Equal(now.Add(48 * time.Hour)) {
+		t.Errorf("expected %v got %v", now.Add(48*time.Hour), v)
+	}
+}
+
+func TestWithTLSCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	certFile, keyFile := writeTestCert(t, dir, now.Add(time.Hour), now)
+	r, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	client, err := NewClient(context.Background(), WithTLSCertReloader(r), WithTransport(&http.Transport{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.opts.tlsConf == nil || client.opts.tlsConf.GetClientCertificate == nil {
+		t.Fatal("expected GetClientCertificate to be set")
+	}
+	if client.insecure {
+		t.Error("expected secure client")
+	}
+
+	// the default transport and the other clients keep their TLS config.
+	client, err = NewClient(context.Background(), WithTLSCertReloader(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf := http.DefaultTransport.(*http.Transport).TLSClientConfig; conf != nil && conf.GetClientCertificate != nil {
+		t.Error("expected the default transport without the client certificate")
+	}
+	tr, ok := client.opts.transport.(*http.Transport)
+	if !ok || tr == http.DefaultTransport || tr.TLSClientConfig == nil || tr.TLSClientConfig.GetClientCertificate == nil {
+		t.Fatalf("expected a transport with the client certificate got %v", client.opts.transport)
+	}
+	other, err := NewClient(context.Background(), WithTLSConfig(&tls.Config{ServerName: "other", MinVersion: tls.VersionTLS12}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.TLSClientConfig.ServerName != "" {
+		t.Errorf("expected the TLS config of the first client got %s", tr.TLSClientConfig.ServerName)
+	}
+	if conf := other.opts.transport.(*http.Transport).TLSClientConfig; conf.ServerName != "other" || conf.GetClientCertificate != nil {
+		t.Errorf("expected the TLS config of the other client got %v", conf)
+	}
+}
@@ -20,6 +20,8 @@ import (
 	"github.com/go-kratos/kratos/v2/transport"
 )
 
+const reasonNodeNotFound = "NODE_NOT_FOUND"
+
 func init() {
 	if selector.GlobalSelector() == nil {
 		selector.SetGlobalSelector(wrr.NewBuilder())
@@ -42,6 +44,7 @@ type ClientOption func(*clientOptions)
 type clientOptions struct {
 	ctx          context.Context
 	tlsConf      *tls.Config
+	certReloader *CertReloader
 	timeout      time.Duration
 	endpoint     string
 	userAgent    string
@@ -53,6 +56,11 @@ type clientOptions struct {
 	discovery    registry.Discovery
 	middleware   []middleware.Middleware
 	block        bool
+	retry        *RetryPolicy
+	hedging      *HedgingPolicy
+	breaker      *CircuitBreaker
+	compress     bool
+	encodings    []string
 }
 
 // WithTransport with client transport.
@@ -139,14 +147,34 @@ func WithTLSConfig(c *tls.Config) ClientOption {
 	}
 }
 
+// WithTLSCertReloader with a reloading client certificate,
+// it is served through the GetClientCertificate of the TLS config.
+func WithTLSCertReloader(r *CertReloader) ClientOption {
+	return func(o *clientOptions) {
+		o.certReloader = r
+	}
+}
+
+// WithCompression with the content codings of the responses, in the order of preference.
+// They are advertised in the Accept-Encoding header of the requests without one, and the
+// responses are decoded transparently. The default codings are zstd, br then gzip.
+func WithCompression(codings ...string) ClientOption {
+	return func(o *clientOptions) {
+		o.compress = true
+		o.encodings = codings
+	}
+}
+
 // Client is an HTTP client.
 type Client struct {
 	opts     clientOptions
 	target   *Target
 	r        *resolver
 	cc       *http.Client
+	sc       *http.Client
 	insecure bool
 	selector selector.Selector
+	budget   *retryBudget
 }
 
 // NewClient returns an HTTP client.
@@ -162,11 +190,30 @@ func NewClient(ctx context.Context, opts ...ClientOption) (*Client, error) {
 	for _, o := range opts {
 		o(&options)
 	}
+	if options.breaker != nil {
+		options.middleware = append([]middleware.Middleware{options.breaker.Middleware()}, options.middleware...)
+		options.nodeFilters = append(options.nodeFilters, options.breaker.NodeFilter())
+	}
+	if options.certReloader != nil {
+		if options.tlsConf == nil {
+			options.tlsConf = &tls.Config{MinVersion: tls.VersionTLS12}
+		} else {
+			options.tlsConf = options.tlsConf.Clone()
+		}
+		options.tlsConf.GetClientCertificate = options.certReloader.GetClientCertificate
+	}
 	if options.tlsConf != nil {
+		// the transport is cloned, as the default transport and the transports
+		// of the other clients must not use the TLS config of this client.
 		if tr, ok := options.transport.(*http.Transport); ok {
+			tr = tr.Clone()
 			tr.TLSClientConfig = options.tlsConf
+			options.transport = tr
 		}
 	}
+	if options.compress {
+		options.transport = newCompressTransport(options.transport, options.encodings)
+	}
 	insecure := options.tlsConf == nil
 	target, err := parseTarget(options.endpoint, insecure)
 	if err != nil {
@@ -192,26 +239,40 @@ func NewClient(ctx context.Context, opts ...ClientOption) (*Client, error) {
 			Timeout:   options.timeout,
 			Transport: options.transport,
 		},
+		sc:       &http.Client{Transport: options.transport},
 		selector: selector,
+		budget:   newRetryBudget(),
 	}, nil
 }
 
 // Invoke makes a rpc call procedure for remote service.
+// The args are sent as is when they are an io.Reader, so the request body is streamed.
 func (client *Client) Invoke(ctx context.Context, method, path string, args interface{}, reply interface{}, opts ...CallOption) error {
-	var (
-		contentType string
-		body        io.Reader
-	)
-	c := defaultCallInfo(path)
+	c := client.newCallInfo(path)
 	for _, o := range opts {
 		if err := o.before(&c); err != nil {
 			return err
 		}
 	}
-	if args != nil {
+	req, err := client.newRequest(ctx, method, path, args, c)
+	if err != nil {
+		return err
+	}
+	return client.invoke(ctx, req, args, reply, c, opts...)
+}
+
+func (client *Client) newRequest(ctx context.Context, method, path string, args interface{}, c callInfo) (*http.Request, error) {
+	var (
+		contentType string
+		body        io.Reader
+	)
+	if r, ok := args.(io.Reader); ok {
+		contentType = c.contentType
+		body = r
+	} else if args != nil {
 		data, err := client.opts.encoder(ctx, c.contentType, args)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		contentType = c.contentType
 		body = bytes.NewReader(data)
@@ -219,14 +280,41 @@ func (client *Client) Invoke(ctx context.Context, method, path string, args inte
 	url := fmt.Sprintf("%s://%s%s", client.target.Scheme, client.target.Authority, path)
 	req, err := http.NewRequest(method, url, body)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if contentType != "" {
-		req.Header.Set("Content-Type", c.contentType)
+		req.Header.Set("Content-Type", contentType)
 	}
 	if client.opts.userAgent != "" {
 		req.Header.Set("User-Agent", client.opts.userAgent)
 	}
+	return req, nil
+}
+
+func (client *Client) newCallInfo(path string) callInfo {
+	c := defaultCallInfo(path)
+	c.retry = client.opts.retry
+	c.hedging = client.opts.hedging
+	return c
+}
+
+func (client *Client) invoke(ctx context.Context, req *http.Request, args interface{}, reply interface{}, c callInfo, opts ...CallOption) error {
+	_, err := client.roundTrip(ctx, req, args, c, opts, func(ctx context.Context, res *http.Response) (interface{}, error) {
+		defer res.Body.Close()
+		if err := client.opts.decoder(ctx, res, reply); err != nil {
+			return nil, err
+		}
+		return reply, nil
+	})
+	return err
+}
+
+// roundTrip sends the request through the client middleware, with the retry and
+// hedging policies of the call, and runs the after hooks of the call options
+// before the response is handled.
+func (client *Client) roundTrip(ctx context.Context, req *http.Request, in interface{}, c callInfo, opts []CallOption,
+	handle func(context.Context, *http.Response) (interface{}, error),
+) (interface{}, error) {
 	ctx = transport.NewClientContext(ctx, &Transport{
 		endpoint:     client.opts.endpoint,
 		reqHeader:    headerCarrier(req.Header),
@@ -234,12 +322,8 @@ func (client *Client) Invoke(ctx context.Context, method, path string, args inte
 		request:      req,
 		pathTemplate: c.pathTemplate,
 	})
-	return client.invoke(ctx, req, args, reply, c, opts...)
-}
-
-func (client *Client) invoke(ctx context.Context, req *http.Request, args interface{}, reply interface{}, c callInfo, opts ...CallOption) error {
 	h := func(ctx context.Context, in interface{}) (interface{}, error) {
-		res, err := client.do(req.WithContext(ctx))
+		res, err := client.doRetry(ctx, req, c)
 		if res != nil {
 			cs := csAttempt{res: res}
 			for _, o := range opts {
@@ -249,59 +333,100 @@ func (client *Client) invoke(ctx context.Context, req *http.Request, args interf
 		if err != nil {
 			return nil, err
 		}
-		defer res.Body.Close()
-		if err := client.opts.decoder(ctx, res, reply); err != nil {
-			return nil, err
-		}
-		return reply, nil
+		return handle(ctx, res)
 	}
 	var p selector.Peer
 	ctx = selector.NewPeerContext(ctx, &p)
 	if len(client.opts.middleware) > 0 {
 		h = middleware.Chain(client.opts.middleware...)(h)
 	}
-	_, err := h(ctx, args)
-	return err
+	return h(ctx, in)
 }
 
-// Do send an HTTP request and decodes the body of response into target.
-// returns an error (of type *Error) if the response status code is not 2xx.
+// Do sends an HTTP request through the client middleware, the selector and the
+// call options, as Invoke does, and returns the response without decoding it.
+// It returns an error (of type *Error) if the response status code is not 2xx.
 func (client *Client) Do(req *http.Request, opts ...CallOption) (*http.Response, error) {
-	c := defaultCallInfo(req.URL.Path)
+	c := client.newCallInfo(req.URL.Path)
 	for _, o := range opts {
 		if err := o.before(&c); err != nil {
 			return nil, err
 		}
 	}
+	if client.opts.userAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", client.opts.userAgent)
+	}
+	res, err := client.roundTrip(req.Context(), req, req, c, opts, func(_ context.Context, res *http.Response) (interface{}, error) {
+		return res, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.(*http.Response), nil
+}
 
-	return client.do(req)
+func (client *Client) do(req *http.Request, filters ...selector.NodeFilter) (*http.Response, error) {
+	done, err := client.selectNode(req, filters...)
+	if err != nil {
+		return nil, err
+	}
+	return client.send(req, done)
 }
 
-func (client *Client) do(req *http.Request) (*http.Response, error) {
-	var done func(context.Context, selector.DoneInfo)
-	if client.r != nil {
-		var (
-			err  error
-			node selector.Node
-		)
-		if node, done, err = client.selector.Select(req.Context(), selector.WithNodeFilter(client.opts.nodeFilters...)); err != nil {
-			return nil, errors.ServiceUnavailable("NODE_NOT_FOUND", err.Error())
+// selectNode picks a node for the request and points the request URL to it,
+// the filters apply in addition to the client node filters. Without discovery
+// the endpoint is the only node, and it is rejected when filtered out.
+func (client *Client) selectNode(req *http.Request, filters ...selector.NodeFilter) (func(context.Context, selector.DoneInfo), error) {
+	if len(filters) > 0 {
+		filters = append(append([]selector.NodeFilter{}, client.opts.nodeFilters...), filters...)
+	} else {
+		filters = client.opts.nodeFilters
+	}
+	if client.r == nil {
+		node := selector.NewNode(req.URL.Scheme, req.URL.Host, nil)
+		nodes := []selector.Node{node}
+		for _, f := range filters {
+			nodes = f(req.Context(), nodes)
 		}
-		if client.insecure {
-			req.URL.Scheme = "http"
-		} else {
-			req.URL.Scheme = "https"
+		if len(nodes) == 0 {
+			return nil, errors.ServiceUnavailable(reasonNodeNotFound, selector.ErrNoAvailable.Error())
 		}
-		req.URL.Host = node.Address()
-		req.Host = node.Address()
+		if p, ok := selector.FromPeerContext(req.Context()); ok {
+			p.Node = node
+		}
+		return nil, nil
+	}
+	node, done, err := client.selector.Select(req.Context(), selector.WithNodeFilter(filters...))
+	if err != nil {
+		return nil, errors.ServiceUnavailable(reasonNodeNotFound, err.Error())
+	}
+	if client.insecure {
+		req.URL.Scheme = "http"
+	} else {
+		req.URL.Scheme = "https"
+	}
+	req.URL.Host = node.Address()
+	req.Host = node.Address()
+	return done, nil
+}
+
+// send sends the request to the selected node and reports the result to done
+// and to the circuit breaker.
+func (client *Client) send(req *http.Request, done func(context.Context, selector.DoneInfo)) (*http.Response, error) {
+	cc := client.cc
+	if isStream(req.Context()) {
+		cc = client.sc
 	}
-	resp, err := client.cc.Do(req)
+	resp, err := cc.Do(req)
 	if err == nil {
 		err = client.opts.errorDecoder(req.Context(), resp)
 	}
 	if done != nil {
 		done(req.Context(), selector.DoneInfo{Err: err})
 	}
+	if client.opts.breaker != nil {
+		client.opts.breaker.mark(req.Context(), req.URL.Host, err)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -333,10 +458,13 @@ func DefaultResponseDecoder(ctx context.Context, res *http.Response, v interface
 	if err != nil {
 		return err
 	}
+	if b, ok := v.(*replyBody); ok {
+		return b.unmarshal(CodecForResponse(res), data)
+	}
 	return CodecForResponse(res).Unmarshal(data, v)
 }
 
-// DefaultErrorDecoder is an HTTP error decoder.
+// DefaultErrorDecoder is an HTTP error decoder, the details of the error are returned by ErrorDetails.
 func DefaultErrorDecoder(ctx context.Context, res *http.Response) error {
 	if res.StatusCode >= 200 && res.StatusCode <= 299 {
 		return nil
@@ -345,8 +473,12 @@ func DefaultErrorDecoder(ctx context.Context, res *http.Response) error {
 	data, err := io.ReadAll(res.Body)
 	if err == nil {
 		e := new(errors.Error)
-		if err = CodecForResponse(res).Unmarshal(data, e); err == nil {
+		codec := CodecForResponse(res)
+		if err = codec.Unmarshal(data, e); err == nil {
 			e.Code = int32(res.StatusCode)
+			if details := unmarshalDetails(codec, data); len(details) > 0 {
+				return WithDetails(e, details...)
+			}
 			return e
 		}
 	}
@@ -355,8 +487,7 @@ func DefaultErrorDecoder(ctx context.Context, res *http.Response) error {
 
 // CodecForResponse get encoding.Codec via http.Response
 func CodecForResponse(r *http.Response) encoding.Codec {
-	codec := encoding.GetCodec(httputil.ContentSubtype(r.Header.Get("Content-Type")))
-	if codec != nil {
+	if codec := codecForMediaType(r.Header.Get("Content-Type")); codec != nil {
 		return codec
 	}
 	return encoding.GetCodec("json")
@@ -10,8 +10,12 @@ import (
 	"io"
 	"log"
 	nethttp "net/http"
+	"net/http/httptest"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -19,6 +23,7 @@ import (
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/transport"
 )
 
 type mockRoundTripper struct{}
@@ -144,19 +149,39 @@ func TestWithErrorDecoder(t *testing.T) {
 	}
 }
 
-type mockDiscovery struct{}
+// mockDiscovery discovers the instances of its endpoints once, or an instance at
+// 127.0.0.1:9001 every 500ms without endpoints.
+type mockDiscovery struct {
+	endpoints []string
+}
 
 func (*mockDiscovery) GetService(ctx context.Context, serviceName string) ([]*registry.ServiceInstance, error) {
 	return nil, nil
 }
 
-func (*mockDiscovery) Watch(ctx context.Context, serviceName string) (registry.Watcher, error) {
-	return &mockWatcher{}, nil
+func (d *mockDiscovery) Watch(ctx context.Context, serviceName string) (registry.Watcher, error) {
+	return &mockWatcher{endpoints: d.endpoints, done: make(chan struct{})}, nil
 }
 
-type mockWatcher struct{}
+type mockWatcher struct {
+	endpoints []string
+	sent      bool
+	done      chan struct{}
+}
 
 func (m *mockWatcher) Next() ([]*registry.ServiceInstance, error) {
+	if len(m.endpoints) > 0 {
+		if m.sent {
+			<-m.done
+			return nil, context.Canceled
+		}
+		m.sent = true
+		instances := make([]*registry.ServiceInstance, 0, len(m.endpoints))
+		for i, e := range m.endpoints {
+			instances = append(instances, &registry.ServiceInstance{ID: strconv.Itoa(i), Name: "zeus", Endpoints: []string{e}})
+		}
+		return instances, nil
+	}
 	instance := &registry.ServiceInstance{
 		ID:        "1",
 		Name:      "kratos",
@@ -168,10 +193,66 @@ func (m *mockWatcher) Next() ([]*registry.ServiceInstance, error) {
 	return []*registry.ServiceInstance{instance}, nil
 }
 
-func (*mockWatcher) Stop() error {
+func (m *mockWatcher) Stop() error {
+	close(m.done)
 	return nil
 }
 
+// testUpstream is an upstream server of the client calls, it replies {"name":host} after
+// its delay, or the status code and reason of its failures to the first failures calls,
+// to all of them when failures is negative.
+type testUpstream struct {
+	*httptest.Server
+	calls    int32
+	canceled int32
+
+	mu     sync.Mutex
+	bodies []string
+}
+
+func newTestUpstream(t *testing.T, delay time.Duration, failures int32, code int, reason string) *testUpstream {
+	t.Helper()
+	u := &testUpstream{}
+	u.Server = newTestServer(t, nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		n := atomic.AddInt32(&u.calls, 1)
+		data, _ := io.ReadAll(r.Body)
+		u.mu.Lock()
+		u.bodies = append(u.bodies, string(data))
+		u.mu.Unlock()
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			atomic.AddInt32(&u.canceled, 1)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if failures < 0 || n <= failures {
+			w.WriteHeader(code)
+			_, _ = w.Write([]byte(`{"reason":"` + reason + `"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"` + r.Host + `"}`))
+	}))
+	return u
+}
+
+// Calls returns the number of calls received.
+func (u *testUpstream) Calls() int32 {
+	return atomic.LoadInt32(&u.calls)
+}
+
+// Canceled returns the number of calls canceled before their reply.
+func (u *testUpstream) Canceled() int32 {
+	return atomic.LoadInt32(&u.canceled)
+}
+
+// Bodies returns the request bodies of the calls.
+func (u *testUpstream) Bodies() []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]string(nil), u.bodies...)
+}
+
 func TestWithDiscovery(t *testing.T) {
 	ov := &mockDiscovery{}
 	o := WithDiscovery(ov)
@@ -364,3 +445,66 @@ func TestNewClient(t *testing.T) {
 		t.Error("err should be equal to encoder error")
 	}
 }
+
+func TestClientDo(t *testing.T) {
+	srv := newTestServer(t, nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		w.Header().Set("X-Trace", r.Header.Get("X-Trace"))
+		w.Header().Set("X-User-Agent", r.UserAgent())
+		_, _ = w.Write([]byte("ok"))
+	}))
+	var (
+		operation string
+		node      string
+	)
+	client, err := NewClient(context.Background(),
+		WithEndpoint(strings.TrimPrefix(srv.URL, "http://")),
+		WithUserAgent("zeus"),
+		WithMiddleware(func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				tr, ok := transport.FromClientContext(ctx)
+				if !ok {
+					t.Fatal("expected client transport in context")
+				}
+				operation = tr.Operation()
+				tr.RequestHeader().Set("X-Trace", "trace-id")
+				reply, err := handler(ctx, req)
+				if p, ok := selector.FromPeerContext(ctx); ok && p.Node != nil {
+					node = p.Node.Address()
+				}
+				return reply, err
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := nethttp.NewRequest(nethttp.MethodGet, srv.URL+"/users", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var header nethttp.Header
+	resp, err := client.Do(req, Operation("/users.v1.User/List"), Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if operation != "/users.v1.User/List" {
+		t.Errorf("expected %s got %s", "/users.v1.User/List", operation)
+	}
+	if v := header.Get("X-Trace"); v != "trace-id" {
+		t.Errorf("expected %s got %s", "trace-id", v)
+	}
+	if v := header.Get("X-User-Agent"); v != "zeus" {
+		t.Errorf("expected %s got %s", "zeus", v)
+	}
+	if host := strings.TrimPrefix(srv.URL, "http://"); node != host {
+		t.Errorf("expected %s got %s", host, node)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "ok" {
+		t.Errorf("expected %s got %s", "ok", data)
+	}
+}
@@ -3,14 +3,15 @@ package http
 import (
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
 
 	"github.com/JellyTony/zeus/internal/httputil"
+	"github.com/gin-gonic/gin"
 	"github.com/go-kratos/kratos/v2/encoding"
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/transport/http/binding"
-	"github.com/gorilla/mux"
 )
 
 // SupportPackageIsVersion1 These constants should not be referenced from any other code.
@@ -40,14 +41,23 @@ type EncodeResponseFunc func(http.ResponseWriter, *http.Request, interface{}) er
 // EncodeErrorFunc is encode error func.
 type EncodeErrorFunc func(http.ResponseWriter, *http.Request, error)
 
-// DefaultRequestVars decodes the request vars to object.
+// DefaultRequestVars decodes the request route params to object.
+// Nested fields can be bound by their field path, e.g. "simple.component".
+// It fails for the requests not served by a Router, which have no route params.
 func DefaultRequestVars(r *http.Request, v interface{}) error {
-	raws := mux.Vars(r)
-	vars := make(url.Values, len(raws))
-	for k, v := range raws {
-		vars[k] = []string{v}
+	c, ok := FromGinContext(r.Context())
+	if !ok {
+		return errors.InternalServer("CODEC", "the route params are not found, the request is not served by a Router")
 	}
-	return binding.BindQuery(vars, v)
+	return binding.BindQuery(paramsValues(c.Params), v)
+}
+
+func paramsValues(params gin.Params) url.Values {
+	vars := make(url.Values, len(params))
+	for _, p := range params {
+		vars.Add(p.Key, p.Value)
+	}
+	return vars
 }
 
 // DefaultRequestQuery decodes the request vars to object.
@@ -63,6 +73,9 @@ func DefaultRequestDecoder(r *http.Request, v interface{}) error {
 	}
 	data, err := io.ReadAll(r.Body)
 	if err != nil {
+		if se := new(errors.Error); errors.As(err, &se) {
+			return se
+		}
 		return errors.BadRequest("CODEC", err.Error())
 	}
 	if len(data) == 0 {
@@ -84,12 +97,12 @@ func DefaultResponseEncoder(w http.ResponseWriter, r *http.Request, v interface{
 		http.Redirect(w, r, url, code)
 		return nil
 	}
-	codec, _ := CodecForRequest(r, "Accept")
+	codec, mediaType := responseMediaType(r)
 	data, err := codec.Marshal(v)
 	if err != nil {
 		return err
 	}
-	w.Header().Set("Content-Type", httputil.ContentType(codec.Name()))
+	w.Header().Set("Content-Type", mediaType)
 	_, err = w.Write(data)
 	if err != nil {
 		return err
@@ -97,29 +110,45 @@ func DefaultResponseEncoder(w http.ResponseWriter, r *http.Request, v interface{
 	return nil
 }
 
-// DefaultErrorEncoder encodes the error to the HTTP response.
+// DefaultErrorEncoder encodes the error to the HTTP response,
+// with the details of the error attached by WithDetails.
 func DefaultErrorEncoder(w http.ResponseWriter, r *http.Request, err error) {
 	se := errors.FromError(err)
-	codec, _ := CodecForRequest(r, "Accept")
-	body, err := codec.Marshal(se)
-	if err != nil {
+	codec, mediaType := responseMediaType(r)
+	body, merr := marshalError(codec, se, ErrorDetails(err))
+	if merr != nil && codec.Name() != "json" {
+		// the status cannot be encoded by every codec, such as the metadata by xml.
+		codec, mediaType = encoding.GetCodec("json"), defaultMediaType
+		body, merr = marshalError(codec, se, ErrorDetails(err))
+	}
+	if merr != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	w.Header().Set("Content-Type", httputil.ContentType(codec.Name()))
+	w.Header().Set("Content-Type", mediaType)
 	w.WriteHeader(int(se.Code))
 	_, _ = w.Write(body)
 }
 
-// CodecForRequest get encoding.Codec via http.Request
+// CodecForRequest get encoding.Codec via http.Request, the codec negotiated with the
+// Accept header, or the codec of the media type of another header such as Content-Type.
 func CodecForRequest(r *http.Request, name string) (encoding.Codec, bool) {
-	for _, accept := range r.Header[name] {
-		contentSubtype := httputil.ContentSubtype(accept)
-		if contentSubtype == "" {
-			contentSubtype = "json"
+	if name == "Accept" {
+		if len(r.Header.Values(name)) == 0 {
+			return encoding.GetCodec("json"), false
+		}
+		codec, _, ok := Negotiate(r)
+		return codec, ok
+	}
+	for _, value := range r.Header[name] {
+		mt, params, err := mime.ParseMediaType(value)
+		if err != nil && httputil.ContentSubtype(value) == "" {
+			return encoding.GetCodec("json"), true
+		}
+		if !utf8Charset(params["charset"]) {
+			continue
 		}
-		codec := encoding.GetCodec(contentSubtype)
-		if codec != nil {
+		if codec := codecForMediaType(mt); codec != nil {
 			return codec, true
 		}
 	}
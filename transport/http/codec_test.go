@@ -2,11 +2,15 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"io"
 	nethttp "net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 
+	"github.com/JellyTony/zeus/internal/testdata/complex"
+	"github.com/gin-gonic/gin"
 	"github.com/go-kratos/kratos/v2/errors"
 )
 
@@ -33,6 +37,37 @@ func TestDefaultRequestDecoder(t *testing.T) {
 	}
 }
 
+func TestDefaultRequestVars(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Params = gin.Params{
+		{Key: "id", Value: "2233"},
+		{Key: "numberOne", Value: "2"},
+		{Key: "simple.component", Value: "zeus"},
+	}
+	req := httptest.NewRequest(nethttp.MethodGet, "/", nil)
+	req = req.WithContext(NewGinContext(context.Background(), c))
+
+	v := new(complex.Complex)
+	if err := DefaultRequestVars(req, v); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if v.Id != 2233 {
+		t.Errorf("expected %v, got %v", 2233, v.Id)
+	}
+	if v.NoOne != "2" {
+		t.Errorf("expected %v, got %v", "2", v.NoOne)
+	}
+	if v.GetSimple().GetComponent() != "zeus" {
+		t.Errorf("expected %v, got %v", "zeus", v.GetSimple().GetComponent())
+	}
+
+	// the request is not served by a Router.
+	err := DefaultRequestVars(httptest.NewRequest(nethttp.MethodGet, "/", nil), new(complex.Complex))
+	if se := errors.FromError(err); se.Code != nethttp.StatusInternalServerError {
+		t.Errorf("expected %v, got %v", nethttp.StatusInternalServerError, err)
+	}
+}
+
 type mockResponseWriter struct {
 	StatusCode int
 	Data       []byte
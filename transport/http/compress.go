@@ -0,0 +1,573 @@
+package http
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	reasonUnsupportedContentEncoding = "UNSUPPORTED_CONTENT_ENCODING"
+
+	defaultCompressMinSize = 1024
+)
+
+// Compressor is a content coding of the request and response bodies.
+type Compressor interface {
+	// NewWriter returns a writer compressing to w, it is flushed by its
+	// Flush() error method if any, and completed by Close.
+	NewWriter(w io.Writer) io.WriteCloser
+	// NewReader returns a reader decompressing r.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// compressors are the registered content codings, zstd, br and gzip are built in.
+var compressors = map[string]Compressor{
+	"gzip": NewGzipCompressor(gzip.DefaultCompression),
+	"br":   NewBrotliCompressor(4),
+	"zstd": NewZstdCompressor(zstd.SpeedDefault),
+}
+
+// defaultEncodings are the content codings in the order of preference.
+var defaultEncodings = []string{"zstd", "br", "gzip"}
+
+// RegisterCompressor registers a compressor for a content coding such as "zstd",
+// it replaces the compressor of the coding if any. It is not safe for concurrent
+// use with the compression, compressors are registered at init time.
+func RegisterCompressor(coding string, c Compressor) {
+	if c == nil {
+		panic("cannot register a nil Compressor")
+	}
+	if coding == "" {
+		panic("cannot register Compressor with an empty coding")
+	}
+	compressors[strings.ToLower(coding)] = c
+}
+
+// GetCompressor returns the compressor of a content coding, "x-gzip" is gzip.
+func GetCompressor(coding string) Compressor {
+	coding = strings.ToLower(strings.TrimSpace(coding))
+	if coding == "x-gzip" {
+		coding = "gzip"
+	}
+	return compressors[coding]
+}
+
+type gzipCompressor struct {
+	level   int
+	writers sync.Pool
+}
+
+// NewGzipCompressor returns the gzip compressor of a compression level of compress/gzip.
+func NewGzipCompressor(level int) Compressor {
+	if _, err := gzip.NewWriterLevel(io.Discard, level); err != nil {
+		panic(err)
+	}
+	return &gzipCompressor{level: level}
+}
+
+func (c *gzipCompressor) NewWriter(w io.Writer) io.WriteCloser {
+	if zw, ok := c.writers.Get().(*gzip.Writer); ok {
+		zw.Reset(w)
+		return &gzipWriter{Writer: zw, pool: &c.writers}
+	}
+	zw, _ := gzip.NewWriterLevel(w, c.level)
+	return &gzipWriter{Writer: zw, pool: &c.writers}
+}
+
+func (c *gzipCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// gzipWriter returns its writer to the pool once closed.
+type gzipWriter struct {
+	*gzip.Writer
+	pool *sync.Pool
+}
+
+func (w *gzipWriter) Close() error {
+	err := w.Writer.Close()
+	w.pool.Put(w.Writer)
+	return err
+}
+
+type brotliCompressor struct {
+	quality int
+}
+
+// NewBrotliCompressor returns the brotli compressor of a quality from 0 to 11.
+func NewBrotliCompressor(quality int) Compressor {
+	return &brotliCompressor{quality: quality}
+}
+
+func (c *brotliCompressor) NewWriter(w io.Writer) io.WriteCloser {
+	return brotli.NewWriterLevel(w, c.quality)
+}
+
+func (c *brotliCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(brotli.NewReader(r)), nil
+}
+
+type zstdCompressor struct {
+	level   zstd.EncoderLevel
+	writers sync.Pool
+}
+
+// NewZstdCompressor returns the zstd compressor of an encoder level of klauspost/compress/zstd.
+func NewZstdCompressor(level zstd.EncoderLevel) Compressor {
+	if _, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(level)); err != nil {
+		panic(err)
+	}
+	return &zstdCompressor{level: level}
+}
+
+func (c *zstdCompressor) NewWriter(w io.Writer) io.WriteCloser {
+	if zw, ok := c.writers.Get().(*zstd.Encoder); ok {
+		zw.Reset(w)
+		return &zstdWriter{Encoder: zw, pool: &c.writers}
+	}
+	// the responses are written by a single goroutine.
+	zw, _ := zstd.NewWriter(w, zstd.WithEncoderLevel(c.level), zstd.WithEncoderConcurrency(1))
+	return &zstdWriter{Encoder: zw, pool: &c.writers}
+}
+
+func (c *zstdCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	// the zstd content coding limits the window to 8MB, RFC 9659.
+	zr, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxWindow(8<<20))
+	if err != nil {
+		return nil, err
+	}
+	return zr.IOReadCloser(), nil
+}
+
+// zstdWriter returns its encoder to the pool once closed.
+type zstdWriter struct {
+	*zstd.Encoder
+	pool *sync.Pool
+}
+
+func (w *zstdWriter) Close() error {
+	err := w.Encoder.Close()
+	w.pool.Put(w.Encoder)
+	return err
+}
+
+// CompressOption is a compression option.
+type CompressOption func(*compressOptions)
+
+type compressOptions struct {
+	minSize   int
+	types     []string
+	encodings []string
+}
+
+// CompressMinSize with the minimum size of the compressed responses, 1024 bytes by default.
+// The responses flushed before are compressed whatever their size.
+func CompressMinSize(n int) CompressOption {
+	return func(o *compressOptions) {
+		o.minSize = n
+	}
+}
+
+// CompressTypes with the media types of the compressed responses, which may be
+// wildcards such as "text/*" or suffix wildcards such as "application/*+json".
+func CompressTypes(mediaTypes ...string) CompressOption {
+	return func(o *compressOptions) {
+		o.types = mediaTypes
+	}
+}
+
+// CompressEncodings with the content codings of the responses in the order of preference,
+// zstd, br then gzip by default. The codings without a registered compressor are ignored.
+func CompressEncodings(codings ...string) CompressOption {
+	return func(o *compressOptions) {
+		o.encodings = codings
+	}
+}
+
+// defaultCompressTypes are the media types compressed by default.
+var defaultCompressTypes = []string{
+	"text/*",
+	"application/json",
+	"application/*+json",
+	"application/xml",
+	"application/*+xml",
+	"application/javascript",
+	"application/x-yaml",
+	"application/yaml",
+	"application/proto",
+	"application/x-protobuf",
+	"application/x-www-form-urlencoded",
+	"image/svg+xml",
+}
+
+// Compress returns a filter that compresses the responses of the content coding
+// their Accept-Encoding header weights best, and decompresses the request bodies
+// of a Content-Encoding. The requests of an unknown content coding are rejected
+// with a 415 error, encoded by the error encoder of the server. The responses are buffered up to the minimum size, a flush
+// sends them at once, so the event streams and Context.Stream are compressed
+// as they are written.
+func Compress(opts ...CompressOption) FilterFunc {
+	o := &compressOptions{
+		minSize:   defaultCompressMinSize,
+		types:     defaultCompressTypes,
+		encodings: defaultEncodings,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := decompressRequest(r); err != nil {
+				w.Header().Set("Accept-Encoding", strings.Join(o.available(), ", "))
+				errorEncoder(r)(w, r, err)
+				return
+			}
+			// the upgraded connections are not http responses.
+			if r.Header.Get("Upgrade") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			addVary(w.Header(), "Accept-Encoding")
+			coding := negotiateEncoding(r.Header.Values("Accept-Encoding"), o.available())
+			if coding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			cw := &compressWriter{ResponseWriter: w, opts: o, coding: coding}
+			defer cw.close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// available returns the content codings that have a registered compressor.
+func (o *compressOptions) available() []string {
+	codings := make([]string, 0, len(o.encodings))
+	for _, coding := range o.encodings {
+		if GetCompressor(coding) != nil {
+			codings = append(codings, strings.ToLower(coding))
+		}
+	}
+	return codings
+}
+
+// decompressRequest replaces the body of a request of a Content-Encoding with its
+// decompressed body, whose length is unknown.
+func decompressRequest(r *http.Request) error {
+	values := contentCodings(r.Header.Values("Content-Encoding"))
+	if len(values) == 0 {
+		return nil
+	}
+	for _, coding := range values {
+		if GetCompressor(coding) == nil {
+			return errors.Newf(http.StatusUnsupportedMediaType, reasonUnsupportedContentEncoding,
+				"unsupported Content-Encoding: %s", coding)
+		}
+	}
+	if r.Body != nil && r.Body != http.NoBody {
+		r.Body = decompressBody(r.Body, values)
+	}
+	r.Header.Del("Content-Encoding")
+	r.Header.Del("Content-Length")
+	r.ContentLength = -1
+	return nil
+}
+
+// contentCodings returns the codings of the Content-Encoding header values in the
+// order they were applied, identity is ignored.
+func contentCodings(values []string) []string {
+	var codings []string
+	for _, value := range values {
+		for _, s := range strings.Split(value, ",") {
+			if s = strings.TrimSpace(s); s != "" && !strings.EqualFold(s, "identity") {
+				codings = append(codings, s)
+			}
+		}
+	}
+	return codings
+}
+
+// decompressBody decodes the body of the content codings in the reverse order they were applied.
+func decompressBody(body io.ReadCloser, codings []string) io.ReadCloser {
+	rc := body
+	for i := len(codings) - 1; i >= 0; i-- {
+		rc = &decompressReader{src: rc, c: GetCompressor(codings[i])}
+	}
+	return rc
+}
+
+// decompressReader starts decompressing at the first read, so the errors of
+// the body are reported by its readers.
+type decompressReader struct {
+	src io.ReadCloser
+	c   Compressor
+	r   io.ReadCloser
+	err error
+}
+
+func (d *decompressReader) Read(p []byte) (int, error) {
+	if d.r == nil && d.err == nil {
+		d.r, d.err = d.c.NewReader(d.src)
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	return d.r.Read(p)
+}
+
+func (d *decompressReader) Close() error {
+	if d.r != nil {
+		_ = d.r.Close()
+	}
+	return d.src.Close()
+}
+
+// negotiateEncoding returns the content coding the Accept-Encoding header values
+// weight best among the available ones, the first of the same weight. It returns
+// an empty coding for the identity.
+func negotiateEncoding(values []string, available []string) string {
+	weights := make(map[string]float64)
+	for _, value := range values {
+		for _, s := range strings.Split(value, ",") {
+			coding, params, _ := strings.Cut(s, ";")
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding == "" {
+				continue
+			}
+			if coding == "x-gzip" {
+				coding = "gzip"
+			}
+			q := 1.0
+			if name, v, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.EqualFold(strings.TrimSpace(name), "q") {
+				f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+				if err != nil || f < 0 || f > 1 {
+					continue
+				}
+				q = f
+			}
+			weights[coding] = q
+		}
+	}
+	best, bestQ := "", 0.0
+	for _, coding := range available {
+		q, ok := weights[coding]
+		if !ok {
+			q = weights["*"]
+		}
+		if q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+// addVary adds a header name to the Vary header unless it is listed.
+func addVary(h http.Header, name string) {
+	for _, value := range h.Values("Vary") {
+		for _, s := range strings.Split(value, ",") {
+			if s = strings.TrimSpace(s); s == "*" || strings.EqualFold(s, name) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", name)
+}
+
+// compressWriter buffers a response until it knows whether to compress it: once
+// the buffer reaches the minimum size, at a flush, or when the handler returns.
+type compressWriter struct {
+	http.ResponseWriter
+	opts    *compressOptions
+	coding  string
+	code    int
+	buf     []byte
+	decided bool
+	w       io.WriteCloser
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.decided || w.code != 0 {
+		return
+	}
+	// the informational responses such as 103 Early Hints are sent as is.
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.code = code
+	if n, err := strconv.Atoi(w.Header().Get("Content-Length")); err == nil && n < w.opts.minSize {
+		w.decide(false)
+	}
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.decided {
+		if w.code == 0 {
+			w.WriteHeader(http.StatusOK)
+		}
+	}
+	if !w.decided {
+		w.buf = append(w.buf, p...)
+		if len(w.buf) < w.opts.minSize {
+			return len(p), nil
+		}
+		w.decide(true)
+		if err := w.writeBuffer(); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if w.w != nil {
+		return w.w.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush sends the buffered response, compressed whatever its size, then flushes
+// the compressor and the underlying writer.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if w.code == 0 {
+			w.code = http.StatusOK
+		}
+		w.decide(true)
+		if w.writeBuffer() != nil {
+			return
+		}
+	}
+	if f, ok := w.w.(interface{ Flush() error }); ok {
+		if f.Flush() != nil {
+			return
+		}
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// decide writes the header of the response, compressed if allowed and its
+// content type is one of the compressed media types.
+func (w *compressWriter) decide(allowed bool) {
+	w.decided = true
+	h := w.Header()
+	if allowed && w.compressible() {
+		h.Set("Content-Encoding", w.coding)
+		h.Del("Content-Length")
+		// the compressed representation is not byte for byte the same.
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+		w.w = GetCompressor(w.coding).NewWriter(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.code)
+}
+
+func (w *compressWriter) compressible() bool {
+	switch w.code {
+	case http.StatusNoContent, http.StatusNotModified, http.StatusPartialContent:
+		return false
+	}
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" {
+		return false
+	}
+	ct := h.Get("Content-Type")
+	if ct == "" {
+		if len(w.buf) == 0 {
+			return false
+		}
+		// the compressed body cannot be sniffed by net/http.
+		ct = http.DetectContentType(w.buf)
+		h.Set("Content-Type", ct)
+	}
+	mt, _, err := mime.ParseMediaType(ct)
+	return err == nil && consumable(w.opts.types, mt)
+}
+
+func (w *compressWriter) writeBuffer() error {
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.w != nil {
+		_, err = w.w.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// close sends the response buffered when the handler returns, it is not
+// compressed as it is smaller than the minimum size.
+func (w *compressWriter) close() {
+	if !w.decided {
+		if w.code == 0 && len(w.buf) == 0 {
+			return
+		}
+		if w.code == 0 {
+			w.code = http.StatusOK
+		}
+		w.decide(false)
+		_ = w.writeBuffer()
+	}
+	if w.w != nil {
+		_ = w.w.Close()
+	}
+}
+
+// compressTransport advertises the content codings of the responses and decodes them.
+type compressTransport struct {
+	base           http.RoundTripper
+	acceptEncoding string
+}
+
+func newCompressTransport(base http.RoundTripper, codings []string) http.RoundTripper {
+	if len(codings) == 0 {
+		codings = defaultEncodings
+	}
+	available := (&compressOptions{encodings: codings}).available()
+	return &compressTransport{base: base, acceptEncoding: strings.Join(available, ", ")}
+}
+
+func (t *compressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.acceptEncoding != "" && req.Header.Get("Accept-Encoding") == "" {
+		// a RoundTripper must not modify the request.
+		req = req.Clone(req.Context())
+		req.Header.Set("Accept-Encoding", t.acceptEncoding)
+	}
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	codings := contentCodings(res.Header.Values("Content-Encoding"))
+	if len(codings) == 0 || req.Method == http.MethodHead {
+		return res, nil
+	}
+	for _, coding := range codings {
+		if GetCompressor(coding) == nil {
+			return res, nil
+		}
+	}
+	res.Body = decompressBody(res.Body, codings)
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.ContentLength = -1
+	res.Uncompressed = true
+	return res, nil
+}
@@ -0,0 +1,355 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	available := []string{"zstd", "br", "gzip"}
+	tests := map[string]string{
+		"":                           "",
+		"gzip":                       "gzip",
+		"x-gzip":                     "gzip",
+		"gzip, deflate, br":          "br",
+		"gzip;q=1, br;q=0.5":         "gzip",
+		"br;q=0, gzip":               "gzip",
+		"*":                          "zstd",
+		"*;q=0.5, zstd;q=0, br;q=0":  "gzip",
+		"deflate":                    "",
+		"identity":                   "",
+		"gzip;q=0":                   "",
+		"GZIP;Q=0.8, br;q=0.8":       "br",
+		"gzip;q=bad, br;q=0.1":       "br",
+		"zstd, gzip;q=0.5, br=1":     "zstd",
+		"zstd;q=0.5, gzip;q=0.5, br": "br",
+	}
+	for accept, want := range tests {
+		var values []string
+		if accept != "" {
+			values = []string{accept}
+		}
+		if got := negotiateEncoding(values, available); got != want {
+			t.Errorf("%s: expected %s got %s", accept, want, got)
+		}
+	}
+}
+
+func decompress(t *testing.T, coding string, data []byte) string {
+	t.Helper()
+	var r io.Reader
+	switch coding {
+	case "gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = zr
+	case "br":
+		r = brotli.NewReader(bytes.NewReader(data))
+	case "zstd":
+		zr, err := zstd.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer zr.Close()
+		r = zr
+	default:
+		return string(data)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat("zeus", 512)
+	srv := NewServer(Filter(Compress(CompressMinSize(1024))))
+	r := srv.Route("/")
+	r.GET("/large", func(ctx Context) error {
+		return ctx.String(200, large)
+	})
+	r.GET("/small", func(ctx Context) error {
+		return ctx.String(200, "zeus")
+	})
+	r.GET("/image", func(ctx Context) error {
+		return ctx.Blob(200, "image/png", []byte(large))
+	})
+	r.GET("/etag", func(ctx Context) error {
+		ctx.Response().Header().Set("ETag", `"v1"`)
+		return ctx.String(200, large)
+	})
+	r.GET("/empty", func(ctx Context) error {
+		ctx.Response().WriteHeader(204)
+		return nil
+	})
+	r.GET("/sniff", func(ctx Context) error {
+		ctx.Response().WriteHeader(200)
+		_, err := ctx.Response().Write([]byte("<html>" + large + "</html>"))
+		return err
+	})
+
+	tests := []struct {
+		path     string
+		method   string
+		accept   string
+		code     int
+		encoding string
+		etag     string
+	}{
+		{"/large", http.MethodGet, "gzip", 200, "gzip", ""},
+		{"/large", http.MethodGet, "br, gzip;q=0.5", 200, "br", ""},
+		{"/large", http.MethodGet, "zstd", 200, "zstd", ""},
+		{"/large", http.MethodGet, "gzip, deflate, br, zstd", 200, "zstd", ""},
+		{"/large", http.MethodGet, "deflate", 200, "", ""},
+		{"/large", http.MethodGet, "", 200, "", ""},
+		{"/small", http.MethodGet, "gzip", 200, "", ""},
+		{"/image", http.MethodGet, "gzip", 200, "", ""},
+		{"/etag", http.MethodGet, "gzip", 200, "gzip", `W/"v1"`},
+		{"/etag", http.MethodGet, "", 200, "", `"v1"`},
+		{"/empty", http.MethodGet, "gzip", 204, "", ""},
+		{"/sniff", http.MethodGet, "gzip", 200, "gzip", ""},
+		{"/sniff", http.MethodGet, "zstd", 200, "zstd", ""},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.path, nil)
+		if test.accept != "" {
+			req.Header.Set("Accept-Encoding", test.accept)
+		}
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, req)
+		if res.Code != test.code {
+			t.Errorf("%s %s: expected %d got %d", test.path, test.accept, test.code, res.Code)
+		}
+		if got := res.Header().Get("Content-Encoding"); got != test.encoding {
+			t.Errorf("%s %s: expected %q got %q", test.path, test.accept, test.encoding, got)
+		}
+		if got := res.Header().Get("Vary"); got != "Accept-Encoding" {
+			t.Errorf("%s %s: expected Vary Accept-Encoding got %q", test.path, test.accept, got)
+		}
+		if test.etag != "" && res.Header().Get("ETag") != test.etag {
+			t.Errorf("%s %s: expected %s got %s", test.path, test.accept, test.etag, res.Header().Get("ETag"))
+		}
+		if test.encoding != "" && res.Header().Get("Content-Length") != "" {
+			t.Errorf("%s %s: unexpected Content-Length %s", test.path, test.accept, res.Header().Get("Content-Length"))
+		}
+		if test.code != 200 {
+			continue
+		}
+		body := decompress(t, test.encoding, res.Body.Bytes())
+		if want := map[string]string{"/small": "zeus", "/sniff": "<html>" + large + "</html>"}[test.path]; want != "" {
+			if body != want {
+				t.Errorf("%s %s: expected %s got %s", test.path, test.accept, want, body)
+			}
+		} else if body != large {
+			t.Errorf("%s %s: unexpected body of %d bytes", test.path, test.accept, len(body))
+		}
+	}
+}
+
+func TestCompressStream(t *testing.T) {
+	next := make(chan struct{})
+	srv := NewServer(Filter(Compress()))
+	srv.Route("/").GET("/stream", func(ctx Context) error {
+		pr, pw := io.Pipe()
+		go func() {
+			_, _ = pw.Write([]byte("hello\n"))
+			<-next
+			_, _ = pw.Write([]byte("world\n"))
+			_ = pw.Close()
+		}()
+		return ctx.Stream(200, "text/plain", pr)
+	})
+	ts := newTestServer(t, srv)
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/stream", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected gzip got %q", res.Header.Get("Content-Encoding"))
+	}
+	zr, err := gzip.NewReader(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the first chunk is flushed before the stream ends.
+	first := make(chan string, 1)
+	go func() {
+		buf := make([]byte, len("hello\n"))
+		n, _ := io.ReadFull(zr, buf)
+		first <- string(buf[:n])
+	}()
+	select {
+	case got := <-first:
+		if got != "hello\n" {
+			t.Errorf("expected %q got %q", "hello\n", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the stream was not flushed")
+	}
+	close(next)
+	rest, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(rest) != "world\n" {
+		t.Errorf("expected %q got %q", "world\n", rest)
+	}
+}
+
+func TestCompressRequest(t *testing.T) {
+	srv := NewServer(Filter(Compress()))
+	r := srv.Route("/")
+	r.Consumes("application/json")
+	r.POST("/users", func(ctx Context) error {
+		var in User
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		return ctx.Result(200, &in)
+	})
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, _ = zw.Write([]byte(`{"name":"zeus"}`))
+	_ = zw.Close()
+	var zs bytes.Buffer
+	zsw := GetCompressor("zstd").NewWriter(&zs)
+	_, _ = zsw.Write([]byte(`{"name":"zeus"}`))
+	_ = zsw.Close()
+
+	tests := []struct {
+		encoding string
+		body     []byte
+		code     int
+		reason   string
+	}{
+		{"gzip", gz.Bytes(), 200, ""},
+		{"zstd", zs.Bytes(), 200, ""},
+		{"identity", []byte(`{"name":"zeus"}`), 200, ""},
+		{"", []byte(`{"name":"zeus"}`), 200, ""},
+		{"compress", []byte(`{"name":"zeus"}`), 415, reasonUnsupportedContentEncoding},
+		{"gzip", []byte(`{"name":"zeus"}`), 400, "CODEC"},
+		{"zstd", []byte(`{"name":"zeus"}`), 400, "CODEC"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/users", bytes.NewReader(test.body))
+		req.Header.Set("Content-Type", "application/json")
+		if test.encoding != "" {
+			req.Header.Set("Content-Encoding", test.encoding)
+		}
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, req)
+		if res.Code != test.code {
+			t.Errorf("%s: expected %d got %d", test.encoding, test.code, res.Code)
+		}
+		if test.reason != "" {
+			se := new(errors.Error)
+			if err := CodecForResponse(res.Result()).Unmarshal(res.Body.Bytes(), se); err != nil || se.Reason != test.reason {
+				t.Errorf("%s: expected %s got %v %v", test.encoding, test.reason, se.Reason, err)
+			}
+			continue
+		}
+		if got := res.Body.String(); got != `{"name":"zeus"}` {
+			t.Errorf("%s: expected %s got %s", test.encoding, `{"name":"zeus"}`, got)
+		}
+	}
+}
+
+func TestCompressRequestErrorEncoder(t *testing.T) {
+	srv := NewServer(Filter(Compress()), ErrorEncoder(ProblemErrorEncoder))
+	srv.Route("/").POST("/users", func(ctx Context) error {
+		return ctx.String(200, "ok")
+	})
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"zeus"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "compress")
+	req.Header.Set("Accept", ProblemContentType)
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, req)
+	// the rejection of the filter is encoded by the error encoder of the server.
+	if res.Code != 415 || res.Header().Get("Content-Type") != ProblemContentType {
+		t.Fatalf("expected %d %s got %d %s", 415, ProblemContentType, res.Code, res.Header().Get("Content-Type"))
+	}
+	var problem map[string]interface{}
+	if err := json.Unmarshal(res.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem["reason"] != reasonUnsupportedContentEncoding || problem["instance"] != "/users" {
+		t.Errorf("unexpected problem %v", problem)
+	}
+	if got := res.Header().Get("Accept-Encoding"); got == "" {
+		t.Error("expected the accepted encodings")
+	}
+}
+
+func TestClientCompression(t *testing.T) {
+	name := strings.Repeat("zeus", 512)
+	var encodings, codings []string
+	srv := NewServer(Filter(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			encodings = append(encodings, r.Header.Get("Accept-Encoding"))
+			next.ServeHTTP(w, r)
+			codings = append(codings, w.Header().Get("Content-Encoding"))
+		})
+	}, Compress()))
+	srv.Route("/").GET("/users", func(ctx Context) error {
+		return ctx.Result(200, &User{Name: name})
+	})
+	ts := newTestServer(t, srv)
+
+	tests := []struct {
+		codings  []string
+		accept   string
+		encoding string
+	}{
+		{nil, "zstd, br, gzip", "zstd"},
+		{[]string{"gzip"}, "gzip", "gzip"},
+		{[]string{"zstd", "br"}, "zstd, br", "zstd"},
+		{[]string{"br", "zstd"}, "br, zstd", "zstd"},
+	}
+	for _, test := range tests {
+		encodings, codings = nil, nil
+		client, err := NewClient(context.Background(),
+			WithEndpoint(strings.TrimPrefix(ts.URL, "http://")),
+			WithCompression(test.codings...),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reply := new(User)
+		if err := client.Invoke(context.Background(), http.MethodGet, "/users", nil, reply); err != nil {
+			t.Fatal(err)
+		}
+		if reply.Name != name {
+			t.Errorf("%v: unexpected reply of %d bytes", test.codings, len(reply.Name))
+		}
+		if len(encodings) != 1 || encodings[0] != test.accept {
+			t.Errorf("%v: expected %s got %v", test.codings, test.accept, encodings)
+		}
+		if len(codings) != 1 || codings[0] != test.encoding {
+			t.Errorf("%v: expected %s got %v", test.codings, test.encoding, codings)
+		}
+	}
+}
@@ -18,7 +18,6 @@ import (
 	"github.com/go-kratos/kratos/v2/transport/http/binding"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gorilla/mux"
 )
 
 var _ Context = (*wrapper)(nil)
@@ -44,6 +43,7 @@ type Context interface {
 	String(int, string) error
 	Blob(int, string, []byte) error
 	Stream(int, string, io.Reader) error
+	EventStream(...EventStreamOption) (*EventStream, error)
 	Reset(http.ResponseWriter, *http.Request)
 
 	HandlerName() string
@@ -166,6 +166,7 @@ type wrapper struct {
 	req    *http.Request
 	res    http.ResponseWriter
 	w      responseWriter
+	stream *EventStream
 }
 
 func (c *wrapper) Header() http.Header {
@@ -173,12 +174,10 @@ func (c *wrapper) Header() http.Header {
 }
 
 func (c *wrapper) Vars() url.Values {
-	raws := mux.Vars(c.req)
-	vars := make(url.Values, len(raws))
-	for k, v := range raws {
-		vars[k] = []string{v}
+	if c.Context == nil {
+		return url.Values{}
 	}
-	return vars
+	return paramsValues(c.Params)
 }
 
 func (c *wrapper) Form() url.Values {
@@ -247,14 +246,57 @@ func (c *wrapper) Blob(code int, contentType string, data []byte) error {
 	return nil
 }
 
+// Stream writes the reader as the response, the response is flushed as the reader
+// yields data so the compressed and proxied responses are not held back.
 func (c *wrapper) Stream(code int, contentType string, rd io.Reader) error {
 	c.res.Header().Set("Content-Type", contentType)
 	c.res.WriteHeader(code)
+	if f, ok := c.res.(http.Flusher); ok {
+		_, err := io.Copy(flushWriter{w: c.res, f: f}, rd)
+		return err
+	}
 	_, err := io.Copy(c.res, rd)
 	return err
 }
 
+// flushWriter flushes each write.
+type flushWriter struct {
+	w io.Writer
+	f http.Flusher
+}
+
+func (w flushWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	if n > 0 {
+		w.f.Flush()
+	}
+	return n, err
+}
+
+// EventStream starts a server-sent event stream as the response, the events are
+// encoded by the response encoder of the server. The stream is closed when the
+// handler returns, and it outlives the server timeout when the route has been
+// registered by Router.SSE. The request context is canceled when the server shuts down.
+func (c *wrapper) EventStream(opts ...EventStreamOption) (*EventStream, error) {
+	ctx, cancel := context.WithCancel(c.req.Context())
+	s, err := newEventStream(ctx, c.res, c.req, c.router.srv.enc, opts...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	s.cancel = cancel
+	c.req = c.req.WithContext(ctx)
+	c.stream = s
+	c.router.srv.trackEventStream(s)
+	return s, nil
+}
+
 func (c *wrapper) Reset(res http.ResponseWriter, req *http.Request) {
+	if c.stream != nil {
+		_ = c.stream.Close()
+		c.router.srv.untrackEventStream(c.stream)
+		c.stream = nil
+	}
 	c.w.reset(res)
 	c.res = res
 	c.req = req
@@ -0,0 +1,181 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/errors"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	// the google.rpc error details are registered for decoding.
+	_ "google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// errorDetailsField is the field number of the details in the proto encoding of the
+// errors.Status, next to its code, reason, message and metadata fields.
+const errorDetailsField protowire.Number = 5
+
+// errorDetails carries the details of an error as the cause of the error.
+type errorDetails struct {
+	details []proto.Message
+	cause   error
+}
+
+func (e *errorDetails) Error() string {
+	if e.cause != nil {
+		return e.cause.Error()
+	}
+	return "error details"
+}
+
+func (e *errorDetails) Unwrap() error { return e.cause }
+
+// WithDetails returns a copy of the error with google.rpc style details such as
+// errdetails.BadRequest, errdetails.RetryInfo, errdetails.ErrorInfo or errdetails.QuotaFailure.
+// The details are encoded by DefaultErrorEncoder and decoded by DefaultErrorDecoder.
+func WithDetails(err *errors.Error, details ...proto.Message) *errors.Error {
+	cause := err.Unwrap()
+	if ed, ok := cause.(*errorDetails); ok {
+		details = append(append([]proto.Message(nil), ed.details...), details...)
+		cause = ed.cause
+	}
+	return err.WithCause(&errorDetails{details: details, cause: cause})
+}
+
+// ErrorDetails returns the details of an error, attached by WithDetails or
+// carried by a gRPC status error.
+func ErrorDetails(err error) []proto.Message {
+	if ed := new(errorDetails); errors.As(err, &ed) {
+		return ed.details
+	}
+	if se := new(errors.Error); errors.As(err, &se) {
+		// the gRPC status of an *errors.Error only holds its own ErrorInfo.
+		return nil
+	}
+	gs, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+	var details []proto.Message
+	for _, d := range gs.Details() {
+		if m, ok := d.(proto.Message); ok {
+			details = append(details, m)
+		}
+	}
+	return details
+}
+
+// marshalError encodes the status of an error and its details, the details are
+// added to the JSON and proto encodings only.
+func marshalError(codec encoding.Codec, se *errors.Error, details []proto.Message) ([]byte, error) {
+	body, err := codec.Marshal(se)
+	if err != nil || len(details) == 0 {
+		return body, err
+	}
+	switch codec.Name() {
+	case "json":
+		body = bytes.TrimRight(body, " \r\n")
+		if len(body) < 2 || body[len(body)-1] != '}' {
+			return body, nil
+		}
+		raws, err := detailsJSON(details)
+		if err != nil {
+			return nil, err
+		}
+		buf := bytes.NewBuffer(body[:len(body)-1])
+		if len(bytes.TrimSpace(body[1:len(body)-1])) > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`"details":[`)
+		buf.Write(bytes.Join(raws, []byte(",")))
+		buf.WriteString("]}")
+		return buf.Bytes(), nil
+	case "proto":
+		for _, d := range details {
+			a, err := anypb.New(d)
+			if err != nil {
+				return nil, err
+			}
+			data, err := proto.Marshal(a)
+			if err != nil {
+				return nil, err
+			}
+			body = protowire.AppendTag(body, errorDetailsField, protowire.BytesType)
+			body = protowire.AppendBytes(body, data)
+		}
+	}
+	return body, nil
+}
+
+// detailsJSON encodes the details as the JSON of google.protobuf.Any.
+func detailsJSON(details []proto.Message) ([][]byte, error) {
+	raws := make([][]byte, 0, len(details))
+	for _, d := range details {
+		a, err := anypb.New(d)
+		if err != nil {
+			return nil, err
+		}
+		data, err := protojson.Marshal(a)
+		if err != nil {
+			return nil, err
+		}
+		raws = append(raws, data)
+	}
+	return raws, nil
+}
+
+// unmarshalDetails decodes the details of an error encoded by marshalError,
+// the details of the unknown types are dropped.
+func unmarshalDetails(codec encoding.Codec, data []byte) []proto.Message {
+	var anys []*anypb.Any
+	switch codec.Name() {
+	case "json":
+		var body struct {
+			Details []json.RawMessage `json:"details"`
+		}
+		if json.Unmarshal(data, &body) != nil {
+			return nil
+		}
+		for _, raw := range body.Details {
+			a := new(anypb.Any)
+			if protojson.Unmarshal(raw, a) == nil {
+				anys = append(anys, a)
+			}
+		}
+	case "proto":
+		for len(data) > 0 {
+			num, typ, n := protowire.ConsumeTag(data)
+			if n < 0 {
+				return nil
+			}
+			data = data[n:]
+			if num == errorDetailsField && typ == protowire.BytesType {
+				v, m := protowire.ConsumeBytes(data)
+				if m < 0 {
+					return nil
+				}
+				if a := new(anypb.Any); proto.Unmarshal(v, a) == nil {
+					anys = append(anys, a)
+				}
+				data = data[m:]
+				continue
+			}
+			if n = protowire.ConsumeFieldValue(num, typ, data); n < 0 {
+				return nil
+			}
+			data = data[n:]
+		}
+	}
+	var details []proto.Message
+	for _, a := range anys {
+		if m, err := a.UnmarshalNew(); err == nil {
+			details = append(details, m)
+		}
+	}
+	return details
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func detailsEqual(a, b []proto.Message) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !proto.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestWithDetails(t *testing.T) {
+	badRequest := &errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "name", Description: "required"}}}
+	retry := &errdetails.RetryInfo{RetryDelay: durationpb.New(time.Second)}
+	cause := io.EOF
+
+	err := WithDetails(errors.BadRequest("INVALID", "invalid").WithCause(cause), badRequest)
+	err = WithDetails(err, retry)
+	if got := ErrorDetails(err); !detailsEqual(got, []proto.Message{badRequest, retry}) {
+		t.Errorf("expected %v got %v", []proto.Message{badRequest, retry}, got)
+	}
+	if !errors.Is(err, errors.BadRequest("INVALID", "")) || !errors.Is(err, cause) {
+		t.Errorf("expected the error and its cause got %v", err)
+	}
+
+	gs, _ := status.New(codes.ResourceExhausted, "quota").WithDetails(&errdetails.QuotaFailure{
+		Violations: []*errdetails.QuotaFailure_Violation{{Subject: "user:1", Description: "daily limit"}},
+	})
+	if got := ErrorDetails(gs.Err()); len(got) != 1 {
+		t.Errorf("expected %d got %d", 1, len(got))
+	}
+	if got := ErrorDetails(errors.BadRequest("INVALID", "invalid")); len(got) != 0 {
+		t.Errorf("expected no details got %v", got)
+	}
+}
+
+func TestErrorDetailsCodec(t *testing.T) {
+	details := []proto.Message{
+		&errdetails.ErrorInfo{Reason: "QUOTA", Domain: "zeus", Metadata: map[string]string{"limit": "10"}},
+		&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{Subject: "user:1", Description: "daily limit"}}},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(time.Minute)},
+	}
+	se := errors.New(http.StatusTooManyRequests, "QUOTA", "quota exceeded").WithMetadata(map[string]string{"user": "1"})
+	for _, accept := range []string{"application/json", "application/x-protobuf"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		DefaultErrorEncoder(w, req, WithDetails(se, details...))
+		res := w.Result()
+		err := DefaultErrorDecoder(context.Background(), res)
+		if got := errors.FromError(err); got.Code != se.Code || got.Reason != se.Reason || got.Message != se.Message || got.Metadata["user"] != "1" {
+			t.Errorf("%s: expected %v got %v", accept, se, got)
+		}
+		if got := ErrorDetails(err); !detailsEqual(got, details) {
+			t.Errorf("%s: expected %v got %v", accept, details, got)
+		}
+	}
+
+	// the details of the unregistered types are dropped.
+	body := `{"code":400,"reason":"INVALID","details":[{"@type":"type.googleapis.com/zeus.Unknown","value":1},{"@type":"type.googleapis.com/google.protobuf.StringValue","value":"known"}]}`
+	res := &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+	err := DefaultErrorDecoder(context.Background(), res)
+	if got := ErrorDetails(err); !detailsEqual(got, []proto.Message{wrapperspb.String("known")}) {
+		t.Errorf("expected %v got %v", wrapperspb.String("known"), got)
+	}
+}
+
+func TestErrorDetailsClient(t *testing.T) {
+	srv := NewServer(Middleware(Validator()))
+	srv.Route("/").POST("/signup", func(ctx Context) error {
+		var in signupRequest
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		_, err := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return req, nil
+		})(ctx, &in)
+		return err
+	})
+	ts := newTestServer(t, srv)
+	client, err := NewClient(context.Background(), WithEndpoint(strings.TrimPrefix(ts.URL, "http://")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.Invoke(context.Background(), http.MethodPost, "/signup", &signupRequest{Name: "ze", Age: 20, Address: &signupAddress{}}, nil)
+	want := &errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+		{Field: "address.city", Description: "required"},
+		{Field: "name", Description: "min=3"},
+	}}
+	if got := ErrorDetails(err); !detailsEqual(got, []proto.Message{want}) {
+		t.Errorf("expected %v got %v", want, got)
+	}
+	if errors.Reason(err) != reasonValidator {
+		t.Errorf("expected %s got %s", reasonValidator, errors.Reason(err))
+	}
+}
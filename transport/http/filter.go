@@ -60,3 +60,21 @@ func FromGinContext(ctx context.Context) (c *gin.Context, ok bool) {
 	c, ok = ctx.Value(ginKey{}).(*gin.Context)
 	return
 }
+
+type errorEncoderKey struct{}
+
+// withErrorEncoder passes the error encoder of the server to the filters by the request context.
+func withErrorEncoder(enc EncodeErrorFunc, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), errorEncoderKey{}, enc)))
+	})
+}
+
+// errorEncoder returns the error encoder of the server serving the request, the filters
+// encode their errors with it, or with DefaultErrorEncoder out of a server.
+func errorEncoder(r *http.Request) EncodeErrorFunc {
+	if enc, ok := r.Context().Value(errorEncoderKey{}).(EncodeErrorFunc); ok {
+		return enc
+	}
+	return DefaultErrorEncoder
+}
@@ -0,0 +1,293 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// HealthStatusUp means the check or server is healthy.
+	HealthStatusUp = "up"
+	// HealthStatusDown means the check or server is unhealthy.
+	HealthStatusDown = "down"
+
+	defaultCheckTimeout = time.Second
+
+	defaultHealthPath     = "/healthz"
+	defaultReadyPath      = "/readyz"
+	defaultReadyAliasPath = "/healthz/ready"
+	defaultLivePath       = "/livez"
+)
+
+// ProbeOption is a health probe option.
+type ProbeOption func(*probeOptions)
+
+type probeOptions struct {
+	health string
+	ready  []string
+	live   string
+}
+
+func defaultProbeOptions() *probeOptions {
+	return &probeOptions{
+		health: defaultHealthPath,
+		ready:  []string{defaultReadyPath, defaultReadyAliasPath},
+		live:   defaultLivePath,
+	}
+}
+
+// HealthPath with the path of the health probe, which reports all the checks and
+// fails while the server drains, default /healthz. An empty path disables the probe.
+func HealthPath(path string) ProbeOption {
+	return func(o *probeOptions) {
+		o.health = path
+	}
+}
+
+// ReadyPath with the paths of the readiness probe, which reports the checks not
+// marked as Liveness and fails while the server drains, default /readyz and
+// /healthz/ready. No paths disable the probe.
+func ReadyPath(paths ...string) ProbeOption {
+	return func(o *probeOptions) {
+		o.ready = paths
+	}
+}
+
+// LivePath with the path of the liveness probe, which reports the Liveness checks,
+// default /livez. An empty path disables the probe.
+func LivePath(path string) ProbeOption {
+	return func(o *probeOptions) {
+		o.live = path
+	}
+}
+
+// HealthProbes with the paths of the health, readiness and liveness probes, served
+// at /healthz, /readyz and /healthz/ready, and /livez by default. The probes are
+// served ahead of the routes, without the middleware of the server, so they are not
+// rejected by its authentication. A route of the service on the path of a probe
+// replaces the probe.
+func HealthProbes(opts ...ProbeOption) ServerOption {
+	return func(s *Server) {
+		for _, opt := range opts {
+			opt(s.probeOpts)
+		}
+	}
+}
+
+// Checker checks the health of the server or one of its dependencies.
+type Checker func(ctx context.Context) error
+
+// CheckOption is a health check option.
+type CheckOption func(*healthCheck)
+
+// CheckTimeout with the timeout of a single check run.
+func CheckTimeout(timeout time.Duration) CheckOption {
+	return func(c *healthCheck) {
+		c.timeout = timeout
+	}
+}
+
+// CheckCache with the duration the check result is cached for.
+func CheckCache(ttl time.Duration) CheckOption {
+	return func(c *healthCheck) {
+		c.ttl = ttl
+	}
+}
+
+// Liveness marks the check as a liveness check, which is reported by the liveness probe.
+// Liveness checks should only fail when the process needs to be restarted.
+func Liveness() CheckOption {
+	return func(c *healthCheck) {
+		c.liveness = true
+	}
+}
+
+// HealthCheck with a named health check.
+// Checks are reported by the health probe, and by the readiness probe unless marked
+// as Liveness, see HealthProbes.
+func HealthCheck(name string, check Checker, opts ...CheckOption) ServerOption {
+	return func(s *Server) {
+		s.AddHealthCheck(name, check, opts...)
+	}
+}
+
+// HealthReport is the health report of the server.
+type HealthReport struct {
+	Status string                  `json:"status"`
+	Checks map[string]*CheckResult `json:"checks,omitempty"`
+}
+
+// CheckResult is the result of a single health check.
+type CheckResult struct {
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type healthCheck struct {
+	name     string
+	check    Checker
+	timeout  time.Duration
+	ttl      time.Duration
+	liveness bool
+
+	mu      sync.Mutex
+	result  *CheckResult
+	expires time.Time
+}
+
+func (c *healthCheck) run(ctx context.Context) *CheckResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if c.result != nil && now.Before(c.expires) {
+		return c.result
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.check(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	result := &CheckResult{
+		Status:    HealthStatusUp,
+		Duration:  time.Since(now).String(),
+		Timestamp: now,
+	}
+	if err != nil {
+		result.Status = HealthStatusDown
+		result.Error = err.Error()
+	}
+	if c.ttl > 0 {
+		c.result = result
+		c.expires = now.Add(c.ttl)
+	}
+	return result
+}
+
+type health struct {
+	mu     sync.RWMutex
+	checks []*healthCheck
+}
+
+func (h *health) add(c *healthCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, c)
+}
+
+func (h *health) report(ctx context.Context, filter func(*healthCheck) bool) *HealthReport {
+	h.mu.RLock()
+	checks := make([]*healthCheck, 0, len(h.checks))
+	for _, c := range h.checks {
+		if filter(c) {
+			checks = append(checks, c)
+		}
+	}
+	h.mu.RUnlock()
+
+	report := &HealthReport{Status: HealthStatusUp}
+	if len(checks) == 0 {
+		return report
+	}
+	results := make([]*CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *healthCheck) {
+			defer wg.Done()
+			results[i] = c.run(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+	report.Checks = make(map[string]*CheckResult, len(checks))
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != HealthStatusUp {
+			report.Status = HealthStatusDown
+		}
+	}
+	return report
+}
+
+// AddHealthCheck registers a named health check.
+func (s *Server) AddHealthCheck(name string, check Checker, opts ...CheckOption) {
+	c := &healthCheck{
+		name:    name,
+		check:   check,
+		timeout: defaultCheckTimeout,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	s.health.add(c)
+}
+
+func (s *Server) registerHealth() {
+	o := s.probeOpts
+	all := func(*healthCheck) bool { return true }
+	readiness := func(c *healthCheck) bool { return !c.liveness }
+	liveness := func(c *healthCheck) bool { return c.liveness }
+	if o.health != "" {
+		s.probes[o.health] = s.healthHandler(all, true)
+	}
+	for _, path := range o.ready {
+		if path != "" {
+			s.probes[path] = s.healthHandler(readiness, true)
+		}
+	}
+	if o.live != "" {
+		s.probes[o.live] = s.healthHandler(liveness, false)
+	}
+}
+
+// serveProbes serves the GET requests of the probes, the others are served by next.
+func (s *Server) serveProbes(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			if h, ok := s.probes[r.URL.Path]; ok {
+				h(w, r)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) healthHandler(filter func(*healthCheck) bool, drain bool) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		report := s.health.report(req.Context(), filter)
+		if drain && atomic.LoadInt32(&s.draining) == 1 {
+			report.Status = HealthStatusDown
+			if report.Checks == nil {
+				report.Checks = make(map[string]*CheckResult, 1)
+			}
+			report.Checks["server"] = &CheckResult{
+				Status:    HealthStatusDown,
+				Error:     "server is draining",
+				Duration:  time.Duration(0).String(),
+				Timestamp: time.Now(),
+			}
+		}
+		code := http.StatusOK
+		if report.Status != HealthStatusUp {
+			code = http.StatusServiceUnavailable
+		}
+		w := responseWriter{}
+		w.reset(res)
+		w.WriteHeader(code)
+		if err := s.enc(&w, req, report); err != nil {
+			s.ene(res, req, err)
+		}
+	}
+}
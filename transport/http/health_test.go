@@ -0,0 +1,162 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+func TestHealth(t *testing.T) {
+	var (
+		calls int32
+		dbErr error
+	)
+	srv := NewServer(
+		HealthCheck("db", func(ctx context.Context) error {
+			atomic.AddInt32(&calls, 1)
+			return dbErr
+		}, CheckCache(time.Minute)),
+		HealthCheck("deadlock", func(ctx context.Context) error {
+			return nil
+		}, Liveness()),
+	)
+	srv.AddHealthCheck("slow", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}, CheckTimeout(10*time.Millisecond))
+
+	get := func(path string) (int, *HealthReport) {
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, httptest.NewRequest(http.MethodGet, path, nil))
+		report := new(HealthReport)
+		if err := json.Unmarshal(res.Body.Bytes(), report); err != nil {
+			t.Fatal(err)
+		}
+		return res.Code, report
+	}
+
+	code, report := get("/livez")
+	if code != http.StatusOK || report.Status != HealthStatusUp {
+		t.Errorf("expected %d %s got %d %s", http.StatusOK, HealthStatusUp, code, report.Status)
+	}
+	if len(report.Checks) != 1 || report.Checks["deadlock"] == nil {
+		t.Errorf("expected deadlock check got %v", report.Checks)
+	}
+
+	code, report = get("/readyz")
+	if code != http.StatusServiceUnavailable || report.Status != HealthStatusDown {
+		t.Errorf("expected %d %s got %d %s", http.StatusServiceUnavailable, HealthStatusDown, code, report.Status)
+	}
+	if report.Checks["slow"].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("expected %v got %v", context.DeadlineExceeded, report.Checks["slow"].Error)
+	}
+	if report.Checks["db"].Status != HealthStatusUp {
+		t.Errorf("expected %s got %s", HealthStatusUp, report.Checks["db"].Status)
+	}
+	if report.Checks["deadlock"] != nil {
+		t.Errorf("expected no liveness check got %v", report.Checks["deadlock"])
+	}
+
+	// the db result is cached.
+	dbErr = errors.New("db down")
+	_, report = get("/healthz")
+	if len(report.Checks) != 3 {
+		t.Errorf("expected %d checks got %v", 3, report.Checks)
+	}
+	if report.Checks["db"].Status != HealthStatusUp {
+		t.Errorf("expected %s got %s", HealthStatusUp, report.Checks["db"].Status)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("expected %d calls got %d", 1, n)
+	}
+}
+
+func TestHealthDraining(t *testing.T) {
+	srv := NewServer()
+	atomic.StoreInt32(&srv.draining, 1)
+	for _, test := range []struct {
+		path string
+		code int
+	}{
+		{"/healthz", http.StatusServiceUnavailable},
+		{"/readyz", http.StatusServiceUnavailable},
+		{"/livez", http.StatusOK},
+	} {
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if res.Code != test.code {
+			t.Errorf("%s: expected %d got %d", test.path, test.code, res.Code)
+		}
+	}
+}
+
+func TestHealthProbes(t *testing.T) {
+	unauthenticated := errors.New("unauthenticated")
+	srv := NewServer(Middleware(func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, unauthenticated
+		}
+	}))
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/healthz", http.StatusOK},
+		{"/readyz", http.StatusOK},
+		{"/healthz/ready", http.StatusOK},
+		{"/livez", http.StatusOK},
+		{"/users", http.StatusInternalServerError},
+	}
+	srv.Route("/").GET("/users", func(ctx Context) error {
+		return ctx.String(200, "users")
+	})
+	// the probes are served without the middleware of the server.
+	for _, test := range tests {
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if res.Code != test.code {
+			t.Errorf("%s: expected %d got %d", test.path, test.code, res.Code)
+		}
+		if test.code != http.StatusOK {
+			continue
+		}
+		if ct := res.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("%s: expected %s got %s", test.path, "application/json", ct)
+		}
+	}
+
+	// the routes of the service replace the probes of the same path.
+	srv = NewServer(HealthProbes(HealthPath(""), ReadyPath("/probes/ready"), LivePath("/probes/live")))
+	srv.Route("/").GET("/probes/live", func(ctx Context) error {
+		return ctx.String(200, "service")
+	})
+	tests = []struct {
+		path string
+		code int
+	}{
+		{"/probes/ready", http.StatusOK},
+		{"/probes/live", http.StatusOK},
+		{"/healthz", http.StatusNotFound},
+		{"/readyz", http.StatusNotFound},
+		{"/healthz/ready", http.StatusNotFound},
+	}
+	for _, test := range tests {
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if res.Code != test.code {
+			t.Errorf("%s: expected %d got %d", test.path, test.code, res.Code)
+		}
+	}
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/probes/live", nil))
+	if res.Body.String() != "service" {
+		t.Errorf("expected %s got %s", "service", res.Body.String())
+	}
+}
@@ -0,0 +1,226 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+const defaultHedgingAttempts = 2
+
+// HedgingPolicy is the hedging policy of client calls. A hedged call sends the
+// request again to a different node when no response is received within the
+// delay, takes the first successful response and cancels the others.
+type HedgingPolicy struct {
+	// Delay is the delay before each hedged attempt is sent.
+	Delay time.Duration
+	// MaxAttempts is the maximum number of attempts, including the original one, default 2.
+	MaxAttempts int
+	// NonIdempotent allows to hedge non idempotent methods such as POST and PATCH.
+	NonIdempotent bool
+}
+
+func (p *HedgingPolicy) allowMethod(method string) bool {
+	return (&RetryPolicy{NonIdempotent: p.NonIdempotent}).allowMethod(method)
+}
+
+// fatal reports whether the error stops the hedging,
+// the client errors are returned as is without waiting for other attempts.
+func (p *HedgingPolicy) fatal(err error) bool {
+	se := new(errors.Error)
+	if !errors.As(err, &se) {
+		return false
+	}
+	return se.Code > 0 && se.Code < http.StatusInternalServerError
+}
+
+// Hedging is the hedging policy call option, it overrides the client hedging policy.
+func Hedging(policy HedgingPolicy) CallOption {
+	return HedgingCallOption{Policy: policy}
+}
+
+// HedgingCallOption is set hedging policy for client call
+type HedgingCallOption struct {
+	EmptyCallOption
+	Policy HedgingPolicy
+}
+
+func (o HedgingCallOption) before(c *callInfo) error {
+	c.hedging = &o.Policy
+	return nil
+}
+
+// WithHedging with client hedging policy.
+func WithHedging(policy HedgingPolicy) ClientOption {
+	return func(o *clientOptions) {
+		o.hedging = &policy
+	}
+}
+
+type hedgedResult struct {
+	index int
+	res   *http.Response
+	err   error
+	peer  *selector.Peer
+}
+
+// cancelBody cancels the context of the winning attempt once its body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// doHedging sends the request, and hedges it according to the policy. Each attempt
+// is sent to a node not used by the previous attempts when there is one, and every
+// attempt reports its own result to the selector.
+func (client *Client) doHedging(ctx context.Context, req *http.Request, policy *HedgingPolicy) (*http.Response, error) {
+	if policy == nil || policy.Delay <= 0 || !policy.allowMethod(req.Method) ||
+		(req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return client.do(req.WithContext(ctx))
+	}
+	attempts := policy.MaxAttempts
+	if attempts < defaultHedgingAttempts {
+		attempts = defaultHedgingAttempts
+	}
+	var (
+		mu      sync.Mutex
+		used    = make(map[string]struct{}, attempts)
+		cancels = make([]context.CancelFunc, 0, attempts)
+		results = make(chan hedgedResult, attempts)
+	)
+	exclude := func(_ context.Context, nodes []selector.Node) []selector.Node {
+		mu.Lock()
+		defer mu.Unlock()
+		filtered := make([]selector.Node, 0, len(nodes))
+		for _, n := range nodes {
+			if _, ok := used[n.Address()]; !ok {
+				filtered = append(filtered, n)
+			}
+		}
+		if len(filtered) == 0 {
+			// every node is in use, let the attempt go to any of them.
+			return nodes
+		}
+		return filtered
+	}
+	start := func(index int) error {
+		p := new(selector.Peer)
+		actx, cancel := context.WithCancel(selector.NewPeerContext(ctx, p))
+		r := req.Clone(actx)
+		if index > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return err
+			}
+			r.Body = body
+		}
+		cancels = append(cancels, cancel)
+		go func() {
+			done, err := client.selectNode(r, exclude)
+			if err != nil {
+				results <- hedgedResult{index: index, err: err, peer: p}
+				return
+			}
+			mu.Lock()
+			used[r.URL.Host] = struct{}{}
+			mu.Unlock()
+			res, err := client.send(r, done)
+			results <- hedgedResult{index: index, res: res, err: err, peer: p}
+		}()
+		return nil
+	}
+	if err := start(0); err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(policy.Delay)
+	defer timer.Stop()
+	var (
+		pending = 1
+		last    hedgedResult
+	)
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if len(cancels) < attempts {
+				if err := start(len(cancels)); err == nil {
+					pending++
+				}
+				timer.Reset(policy.Delay)
+			}
+		case r := <-results:
+			pending--
+			if r.err == nil || policy.fatal(r.err) {
+				client.finishHedging(ctx, r, cancels, results, pending)
+				if r.err != nil {
+					return nil, r.err
+				}
+				r.res.Body = &cancelBody{ReadCloser: r.res.Body, cancel: cancels[r.index]}
+				return r.res, nil
+			}
+			cancels[r.index]()
+			last = r
+			if ctx.Err() != nil {
+				continue
+			}
+			// send the next attempt at once instead of waiting for the delay.
+			if len(cancels) < attempts {
+				if err := start(len(cancels)); err == nil {
+					pending++
+				}
+				resetTimer(timer, policy.Delay)
+			}
+		}
+	}
+	if p, ok := selector.FromPeerContext(ctx); ok && last.peer != nil {
+		p.Node = last.peer.Node
+	}
+	return nil, last.err
+}
+
+func resetTimer(t *time.Timer, d time.Duration) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+	t.Reset(d)
+}
+
+// finishHedging records the node of the winning attempt in the peer of the call,
+// cancels the other attempts and releases their responses in the background.
+func (client *Client) finishHedging(ctx context.Context, winner hedgedResult, cancels []context.CancelFunc, results <-chan hedgedResult, pending int) {
+	if p, ok := selector.FromPeerContext(ctx); ok {
+		p.Node = winner.peer.Node
+	}
+	for i, cancel := range cancels {
+		if i != winner.index {
+			cancel()
+		}
+	}
+	if winner.err != nil {
+		cancels[winner.index]()
+	}
+	if pending == 0 {
+		return
+	}
+	go func() {
+		for i := 0; i < pending; i++ {
+			if r := <-results; r.res != nil {
+				r.res.Body.Close()
+			}
+		}
+	}()
+}
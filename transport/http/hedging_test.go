@@ -0,0 +1,129 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+func TestHedgingPolicy(t *testing.T) {
+	p := &HedgingPolicy{}
+	if !p.allowMethod(http.MethodGet) {
+		t.Error("expected GET to be hedged")
+	}
+	if p.allowMethod(http.MethodPost) {
+		t.Error("expected POST not to be hedged")
+	}
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{context.DeadlineExceeded, false},
+		{errors.ServiceUnavailable("", ""), false},
+		{errors.BadRequest("", ""), true},
+		{errors.NotFound("", ""), true},
+	}
+	for _, test := range tests {
+		if got := p.fatal(test.err); got != test.want {
+			t.Errorf("expected %v got %v for %v", test.want, got, test.err)
+		}
+	}
+}
+
+func TestClientHedging(t *testing.T) {
+	slow := newTestUpstream(t, 2*time.Second, 0, 0, "")
+	fast := newTestUpstream(t, 0, 0, 0, "")
+	var filtered int32
+	client, err := NewClient(context.Background(),
+		WithEndpoint("discovery:///zeus"),
+		WithDiscovery(&mockDiscovery{endpoints: []string{slow.URL, fast.URL}}),
+		WithBlock(),
+		WithNodeFilter(func(_ context.Context, nodes []selector.Node) []selector.Node {
+			atomic.AddInt32(&filtered, 1)
+			return nodes
+		}),
+		WithHedging(HedgingPolicy{Delay: 20 * time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	fastHost := strings.TrimPrefix(fast.URL, "http://")
+	for i := 0; i < 10; i++ {
+		var (
+			reply User
+			start = time.Now()
+		)
+		if err := client.Invoke(context.Background(), http.MethodGet, "/users", nil, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if reply.Name != fastHost {
+			t.Errorf("expected %s got %s", fastHost, reply.Name)
+		}
+		if d := time.Since(start); d > time.Second {
+			t.Errorf("expected hedged call to be fast got %v", d)
+		}
+	}
+	if n := fast.Calls(); n != 10 {
+		t.Errorf("expected %d calls got %d", 10, n)
+	}
+	// an attempt is hedged only when the first one goes to the slow node.
+	if n, want := atomic.LoadInt32(&filtered), 10+slow.Calls(); n != want {
+		t.Errorf("expected %d selections got %d", want, n)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if calls, canceled := slow.Calls(), slow.Canceled(); calls != canceled {
+		t.Errorf("expected %d canceled got %d", calls, canceled)
+	}
+}
+
+func TestClientHedgingFatal(t *testing.T) {
+	srv := newTestUpstream(t, 0, -1, http.StatusNotFound, "")
+	client, err := NewClient(context.Background(),
+		WithEndpoint(strings.TrimPrefix(srv.URL, "http://")),
+		WithHedging(HedgingPolicy{Delay: time.Second, MaxAttempts: 3}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	err = client.Invoke(context.Background(), http.MethodGet, "/users", nil, &User{})
+	if code := errors.Code(err); code != http.StatusNotFound {
+		t.Errorf("expected %d got %d", http.StatusNotFound, code)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("expected no hedging got %v", d)
+	}
+	if n := srv.Calls(); n != 1 {
+		t.Errorf("expected %d calls got %d", 1, n)
+	}
+}
+
+func TestClientHedgingFailover(t *testing.T) {
+	srv := newTestUpstream(t, 0, -1, http.StatusServiceUnavailable, "")
+	client, err := NewClient(context.Background(),
+		WithEndpoint(strings.TrimPrefix(srv.URL, "http://")),
+		WithHedging(HedgingPolicy{Delay: time.Second, MaxAttempts: 3}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	err = client.Invoke(context.Background(), http.MethodGet, "/users", nil, &User{})
+	if code := errors.Code(err); code != http.StatusServiceUnavailable {
+		t.Errorf("expected %d got %d", http.StatusServiceUnavailable, code)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("expected failed attempts to be hedged at once got %v", d)
+	}
+	if n := srv.Calls(); n != 3 {
+		t.Errorf("expected %d calls got %d", 3, n)
+	}
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const reasonRequestEntityTooLarge = "REQUEST_ENTITY_TOO_LARGE"
+
+// BodyLimit returns a middleware that limits the request body size of a route.
+// Requests larger than n bytes are rejected with a 413 error.
+func BodyLimit(n int64) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				if ht, ok := tr.(Transporter); ok && ht.Request() != nil {
+					if err := limitBody(ht.Request(), n); err != nil {
+						return nil, err
+					}
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+func errBodyTooLarge(n int64) error {
+	return errors.Newf(http.StatusRequestEntityTooLarge, reasonRequestEntityTooLarge, "request body too large, limit is %d bytes", n)
+}
+
+// limitBody rejects the request if the declared content length exceeds n,
+// otherwise wraps the body so that reading past n bytes fails.
+func limitBody(req *http.Request, n int64) error {
+	if req.ContentLength > n {
+		return errBodyTooLarge(n)
+	}
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	if mr, ok := req.Body.(*maxBytesReader); ok && mr.limit <= n {
+		return nil
+	}
+	req.Body = &maxBytesReader{r: req.Body, n: n, limit: n}
+	return nil
+}
+
+type maxBytesReader struct {
+	r     io.ReadCloser
+	n     int64
+	limit int64
+	err   error
+}
+
+func (l *maxBytesReader) Read(p []byte) (n int, err error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	// read one extra byte to detect whether the body exceeds the limit.
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err = l.r.Read(p)
+	if int64(n) <= l.n {
+		l.n -= int64(n)
+		l.err = err
+		return n, err
+	}
+	n = int(l.n)
+	l.n = 0
+	l.err = errBodyTooLarge(l.limit)
+	return n, l.err
+}
+
+func (l *maxBytesReader) Close() error {
+	return l.r.Close()
+}
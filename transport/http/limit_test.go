@@ -0,0 +1,80 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestMaxBytesReader(t *testing.T) {
+	r := &maxBytesReader{r: io.NopCloser(strings.NewReader("hello")), n: 5, limit: 5}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("expected %s, got %s", "hello", data)
+	}
+
+	r = &maxBytesReader{r: io.NopCloser(strings.NewReader("hello world")), n: 5, limit: 5}
+	data, err = io.ReadAll(r)
+	if errors.Code(err) != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %v", err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("expected %s, got %s", "hello", data)
+	}
+}
+
+func TestBodyLimit(t *testing.T) {
+	srv := NewServer(MaxBodySize(20))
+	route := srv.Route("/")
+	route.POST("/users", func(ctx Context) error {
+		u := new(User)
+		if err := ctx.Bind(u); err != nil {
+			return err
+		}
+		return ctx.Result(200, u)
+	}, BodyLimit(8))
+	route.POST("/groups", func(ctx Context) error {
+		u := new(User)
+		if err := ctx.Bind(u); err != nil {
+			return err
+		}
+		return ctx.Result(200, u)
+	})
+
+	tests := []struct {
+		path    string
+		body    string
+		chunked bool
+		code    int
+	}{
+		{"/users", `{}`, false, http.StatusOK},
+		{"/users", `{"name":"kratos"}`, false, http.StatusRequestEntityTooLarge},
+		{"/users", `{"name":"kratos"}`, true, http.StatusRequestEntityTooLarge},
+		{"/groups", `{"name":"kratos"}`, false, http.StatusOK},
+		{"/groups", `{"name":"kratos","x":1}`, false, http.StatusRequestEntityTooLarge},
+		{"/groups", `{"name":"kratos","x":1}`, true, http.StatusRequestEntityTooLarge},
+	}
+	for _, test := range tests {
+		var body io.Reader = strings.NewReader(test.body)
+		if test.chunked {
+			body = io.MultiReader(body)
+		}
+		req := httptest.NewRequest(http.MethodPost, test.path, body)
+		req.Header.Set("Content-Type", appJSONStr)
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, req)
+		if res.Code != test.code {
+			t.Errorf("%s %s: expected %d, got %d", test.path, test.body, test.code, res.Code)
+		}
+		if test.code == http.StatusRequestEntityTooLarge && !strings.Contains(res.Body.String(), reasonRequestEntityTooLarge) {
+			t.Errorf("expected reason %s, got %s", reasonRequestEntityTooLarge, res.Body.String())
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package http
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+const (
+	reasonNotAcceptable        = "NOT_ACCEPTABLE"
+	reasonUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+
+	defaultMediaType = "application/json"
+	// producesKey is the gin context key of the media types produced by the router of a route.
+	producesKey = "zeus/produces"
+)
+
+// codecAliases maps the subtypes in use for the codecs registered under another name.
+var codecAliases = map[string]string{
+	"x-protobuf":          "proto",
+	"protobuf":            "proto",
+	"x-proto":             "proto",
+	"vnd.google.protobuf": "proto",
+	"x-yaml":              "yaml",
+	"x-json":              "json",
+}
+
+// codecForMediaType returns the codec of a media type such as "application/json",
+// the structured syntax suffixes select the codec of the suffix: application/vnd.api+json
+// is encoded by the json codec.
+func codecForMediaType(mediaType string) encoding.Codec {
+	i := strings.Index(mediaType, "/")
+	if i < 0 {
+		return nil
+	}
+	subtype := strings.ToLower(strings.TrimSpace(mediaType[i+1:]))
+	if j := strings.Index(subtype, ";"); j >= 0 {
+		subtype = strings.TrimSpace(subtype[:j])
+	}
+	if alias, ok := codecAliases[subtype]; ok {
+		subtype = alias
+	}
+	if codec := encoding.GetCodec(subtype); codec != nil {
+		return codec
+	}
+	if j := strings.LastIndex(subtype, "+"); j >= 0 {
+		return codecForMediaType("application/" + subtype[j+1:])
+	}
+	return nil
+}
+
+// utf8Charset reports whether the charset is absent or UTF-8, the charset of the codecs.
+func utf8Charset(charset string) bool {
+	return charset == "" || strings.EqualFold(charset, "utf-8") || strings.EqualFold(charset, "utf8")
+}
+
+// mediaRange is a media range of the Accept header.
+type mediaRange struct {
+	typ     string
+	subtype string
+	charset string
+	q       float64
+}
+
+// parseMediaRanges parses the Accept header values, the ranges are sorted by
+// their weight, then their specificity.
+func parseMediaRanges(values []string) []mediaRange {
+	var ranges []mediaRange
+	for _, value := range values {
+		for _, s := range strings.Split(value, ",") {
+			if strings.TrimSpace(s) == "" {
+				continue
+			}
+			mt, params, err := mime.ParseMediaType(s)
+			if err != nil {
+				continue
+			}
+			i := strings.Index(mt, "/")
+			if i < 0 {
+				if mt != "*" {
+					continue
+				}
+				// a single "*" is used for "*/*" by some clients.
+				mt, i = "*/*", 1
+			}
+			r := mediaRange{typ: mt[:i], subtype: mt[i+1:], charset: params["charset"], q: 1}
+			if q, ok := params["q"]; ok {
+				if r.q, err = strconv.ParseFloat(q, 64); err != nil || r.q < 0 || r.q > 1 {
+					continue
+				}
+			}
+			ranges = append(ranges, r)
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].q != ranges[j].q {
+			return ranges[i].q > ranges[j].q
+		}
+		return ranges[i].specificity() > ranges[j].specificity()
+	})
+	return ranges
+}
+
+func (m mediaRange) specificity() int {
+	switch {
+	case m.typ == "*":
+		return 0
+	case m.subtype == "*":
+		return 1
+	case m.charset != "":
+		return 3
+	}
+	return 2
+}
+
+// match returns the specificity of the range if it matches the media type.
+func (m mediaRange) match(mediaType string) (int, bool) {
+	typ, subtype := mediaType, ""
+	if i := strings.Index(mediaType, "/"); i >= 0 {
+		typ, subtype = mediaType[:i], mediaType[i+1:]
+	}
+	switch {
+	case !utf8Charset(m.charset):
+		return 0, false
+	case m.typ == "*":
+	case !strings.EqualFold(m.typ, typ):
+		return 0, false
+	case m.subtype == "*":
+	case !strings.EqualFold(m.subtype, subtype):
+		return 0, false
+	}
+	return m.specificity(), true
+}
+
+// quality returns the weight of a media type given by its most specific range,
+// and whether that range names the media type rather than a wildcard.
+func quality(ranges []mediaRange, mediaType string) (float64, bool) {
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		if s, ok := r.match(mediaType); ok && s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q, specificity >= 2
+}
+
+// Negotiate returns the codec and the media type of the response to the request, the offered
+// media type its Accept header weights best, as of RFC 9110. The offers are the produced media
+// types, or the media types of the registered codecs when none is given; the offers without a
+// codec are ignored. It reports false with the json codec if the request accepts none of them.
+func Negotiate(r *http.Request, produces ...string) (encoding.Codec, string, bool) {
+	ranges := parseMediaRanges(r.Header.Values("Accept"))
+	offers := produces
+	if len(offers) == 0 {
+		offers = defaultOffers(ranges)
+	}
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if codecForMediaType(offer) == nil {
+			continue
+		}
+		if len(ranges) == 0 {
+			best = offer
+			break
+		}
+		if q, _ := quality(ranges, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	if best == "" {
+		return encoding.GetCodec("json"), defaultMediaType, false
+	}
+	return codecForMediaType(best), best, true
+}
+
+// defaultOffers returns the media types named by the ranges that have a codec, then application/json.
+// The problem details are not offered, they are the format of ProblemErrorEncoder.
+func defaultOffers(ranges []mediaRange) []string {
+	offers := make([]string, 0, len(ranges)+1)
+	for _, r := range ranges {
+		if r.typ == "*" || r.subtype == "*" || strings.EqualFold(r.typ+"/"+r.subtype, ProblemContentType) {
+			continue
+		}
+		if mt := r.typ + "/" + r.subtype; codecForMediaType(mt) != nil {
+			offers = append(offers, strings.ToLower(mt))
+		}
+	}
+	return append(offers, defaultMediaType)
+}
+
+// responseMediaType returns the codec and the media type of the response to the request,
+// negotiated among the media types produced by the router of the route.
+func responseMediaType(r *http.Request) (encoding.Codec, string) {
+	var produces []string
+	if c, ok := FromGinContext(r.Context()); ok {
+		if v, ok := c.Get(producesKey); ok {
+			produces = v.([]string)
+		}
+	}
+	codec, mediaType, _ := Negotiate(r, produces...)
+	return codec, mediaType
+}
+
+// negotiate checks the request against the media types consumed and produced by the router.
+func (r *Router) negotiate(c *gin.Context) error {
+	req := c.Request
+	if len(r.consumes) > 0 && req.ContentLength != 0 {
+		mt, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if err != nil || !utf8Charset(params["charset"]) || !consumable(r.consumes, mt) {
+			return errors.Newf(http.StatusUnsupportedMediaType, reasonUnsupportedMediaType,
+				"unsupported Content-Type: %s", req.Header.Get("Content-Type"))
+		}
+	}
+	if len(r.produces) > 0 {
+		if _, _, ok := Negotiate(req, r.produces...); !ok {
+			return errors.Newf(http.StatusNotAcceptable, reasonNotAcceptable,
+				"none of the media types %s is acceptable", strings.Join(r.produces, ", "))
+		}
+		c.Set(producesKey, r.produces)
+	}
+	return nil
+}
+
+// consumable reports whether the media type is one of the consumed media types,
+// which may be wildcards such as "application/*" or suffix wildcards such as "application/*+json".
+func consumable(consumes []string, mediaType string) bool {
+	for _, consume := range consumes {
+		i := strings.Index(consume, "/")
+		if i < 0 {
+			continue
+		}
+		typ, subtype := consume[:i], consume[i+1:]
+		if strings.HasPrefix(subtype, "*+") {
+			if !strings.HasSuffix(strings.ToLower(mediaType), subtype[1:]) {
+				continue
+			}
+			subtype = "*"
+		}
+		if _, ok := (mediaRange{typ: typ, subtype: subtype}).match(mediaType); ok {
+			return true
+		}
+	}
+	return false
+}
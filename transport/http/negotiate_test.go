@@ -0,0 +1,166 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestCodecForMediaType(t *testing.T) {
+	tests := map[string]string{
+		"application/json":                  "json",
+		"application/json; charset=utf-8":   "json",
+		"application/vnd.api+json":          "json",
+		"application/problem+json":          "json",
+		"application/x-protobuf":            "proto",
+		"application/proto":                 "proto",
+		"text/xml":                          "xml",
+		"application/atom+xml":              "xml",
+		"application/x-yaml":                "yaml",
+		"application/x-www-form-urlencoded": "x-www-form-urlencoded",
+		"text/html":                         "",
+		"json":                              "",
+	}
+	for mediaType, want := range tests {
+		var got string
+		if codec := codecForMediaType(mediaType); codec != nil {
+			got = codec.Name()
+		}
+		if got != want {
+			t.Errorf("%s: expected %s got %s", mediaType, want, got)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept    string
+		produces  []string
+		mediaType string
+		ok        bool
+	}{
+		{"", nil, "application/json", true},
+		{"", []string{"application/xml", "application/json"}, "application/xml", true},
+		{"application/xml", nil, "application/xml", true},
+		{"application/xml;q=0.5, application/json", nil, "application/json", true},
+		{"application/json;q=0.5, application/xml;q=0.8", nil, "application/xml", true},
+		{"application/vnd.api+json", nil, "application/vnd.api+json", true},
+		{"text/html, application/xhtml+xml, application/xml;q=0.9, */*;q=0.8", nil, "application/xhtml+xml", true},
+		{"*/*", nil, "application/json", true},
+		{"*", nil, "application/json", true},
+		{"application/*", []string{"text/xml", "application/json"}, "application/json", true},
+		{"text/*;q=0.9, application/json;q=0.5", []string{"application/json", "text/xml"}, "text/xml", true},
+		// the most specific range gives the weight of a media type.
+		{"application/*;q=0.9, application/json;q=0.1", []string{"application/json", "application/xml"}, "application/xml", true},
+		{"*/*, application/json;q=0", []string{"application/json", "application/xml"}, "application/xml", true},
+		{"application/json;charset=utf-8", nil, "application/json", true},
+		{"application/json;charset=iso-8859-1", nil, "application/json", false},
+		{"text/html", nil, "application/json", false},
+		{"text/html", []string{"application/json"}, "application/json", false},
+		{"application/json;q=0", nil, "application/json", false},
+		{"application/json;q=2", nil, "application/json", true},
+		// the problem details are the format of the problem error encoder.
+		{"application/problem+json", nil, "application/json", false},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if test.accept != "" {
+			req.Header.Set("Accept", test.accept)
+		}
+		codec, mediaType, ok := Negotiate(req, test.produces...)
+		if mediaType != test.mediaType || ok != test.ok {
+			t.Errorf("%s %v: expected %s %v got %s %v", test.accept, test.produces, test.mediaType, test.ok, mediaType, ok)
+		}
+		if codec == nil || codec.Name() != codecForMediaType(test.mediaType).Name() {
+			t.Errorf("%s %v: expected the codec of %s got %v", test.accept, test.produces, test.mediaType, codec)
+		}
+	}
+}
+
+func TestAcceptsProblem(t *testing.T) {
+	tests := map[string]bool{
+		"application/problem+json":                            true,
+		"application/json, application/problem+json":          true,
+		"application/json, application/problem+json;q=0.5":    false,
+		"application/problem+json;q=0.5, application/*;q=0.1": true,
+		"*/*":              false,
+		"application/*":    false,
+		"application/json": false,
+		"":                 false,
+	}
+	for accept, want := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", accept)
+		if got := acceptsProblem(req); got != want {
+			t.Errorf("%s: expected %v got %v", accept, want, got)
+		}
+	}
+}
+
+func TestRouterConsumesProduces(t *testing.T) {
+	srv := NewServer()
+	r := srv.Route("/v1")
+	r.Consumes("application/json", "application/*+json")
+	r.Produces("application/json", "application/xml")
+	r.POST("/users", func(ctx Context) error {
+		var in User
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		return ctx.Result(200, &in)
+	})
+	g := r.Group("/admin")
+	g.Produces("application/xml")
+	g.GET("/users", func(ctx Context) error {
+		return ctx.Result(200, &User{Name: "zeus"})
+	})
+
+	tests := []struct {
+		method      string
+		path        string
+		contentType string
+		accept      string
+		body        string
+		code        int
+		reason      string
+		response    string
+	}{
+		{http.MethodPost, "/v1/users", "application/json", "", `{"name":"zeus"}`, 200, "", "application/json"},
+		{http.MethodPost, "/v1/users", "application/json; charset=utf-8", "application/xml", `{"name":"zeus"}`, 200, "", "application/xml"},
+		{http.MethodPost, "/v1/users", "application/vnd.api+json", "application/*;q=0.5, application/xml", `{"name":"zeus"}`, 200, "", "application/xml"},
+		{http.MethodPost, "/v1/users", "application/xml", "", `<User><name>zeus</name></User>`, 415, reasonUnsupportedMediaType, ""},
+		{http.MethodPost, "/v1/users", "application/json; charset=latin1", "", `{"name":"zeus"}`, 415, reasonUnsupportedMediaType, ""},
+		{http.MethodPost, "/v1/users", "", "", `{"name":"zeus"}`, 415, reasonUnsupportedMediaType, ""},
+		// the errors fall back to json when the negotiated codec cannot encode them.
+		{http.MethodPost, "/v1/users", "text/plain", "application/xml", "zeus", 415, reasonUnsupportedMediaType, "application/json"},
+		{http.MethodPost, "/v1/users", "application/json", "text/html", `{"name":"zeus"}`, 406, reasonNotAcceptable, ""},
+		{http.MethodGet, "/v1/admin/users", "", "application/json", "", 406, reasonNotAcceptable, ""},
+		{http.MethodGet, "/v1/admin/users", "", "*/*", "", 200, "", "application/xml"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		if test.contentType != "" {
+			req.Header.Set("Content-Type", test.contentType)
+		}
+		if test.accept != "" {
+			req.Header.Set("Accept", test.accept)
+		}
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, req)
+		if res.Code != test.code {
+			t.Errorf("%s %s %s: expected %d got %d", test.path, test.contentType, test.accept, test.code, res.Code)
+		}
+		if test.reason != "" {
+			se := new(errors.Error)
+			if err := CodecForResponse(res.Result()).Unmarshal(res.Body.Bytes(), se); err != nil || se.Reason != test.reason {
+				t.Errorf("%s %s %s: expected %s got %v %v", test.path, test.contentType, test.accept, test.reason, se.Reason, err)
+			}
+		}
+		if test.response != "" && res.Header().Get("Content-Type") != test.response {
+			t.Errorf("%s %s %s: expected %s got %s", test.path, test.contentType, test.accept, test.response, res.Header().Get("Content-Type"))
+		}
+	}
+}
@@ -0,0 +1,789 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"html/template"
+	"io"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JellyTony/zeus/internal/pathtemplate"
+	"github.com/JellyTony/zeus/internal/swaggerui"
+	"github.com/gin-gonic/gin"
+	"github.com/go-kratos/kratos/v2/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	openAPIVersion     = "3.1.0"
+	defaultOpenAPIPath = "/openapi.json"
+)
+
+// OpenAPIOption is an OpenAPI document option.
+type OpenAPIOption func(*openAPIOptions)
+
+type openAPIOptions struct {
+	path        string
+	title       string
+	version     string
+	description string
+	swaggerUI   string
+	redoc       string
+	swaggerJS   OpenAPIAsset
+	swaggerCSS  OpenAPIAsset
+	redocJS     OpenAPIAsset
+}
+
+// OpenAPIAsset is a script or a stylesheet of the documentation pages. It is loaded
+// with its Subresource Integrity hash when it is set, such as "sha384-...", so the
+// browser refuses an asset altered by the server it is loaded from.
+type OpenAPIAsset struct {
+	URL       string
+	Integrity string
+}
+
+// The Redoc bundle is pinned to an exact version, the Swagger UI assets are embedded.
+var defaultRedocJS = OpenAPIAsset{URL: "https://unpkg.com/redoc@2.1.5/bundles/redoc.standalone.js"}
+
+// OpenAPIPath with the path the document is served at, default /openapi.json.
+func OpenAPIPath(path string) OpenAPIOption {
+	return func(o *openAPIOptions) {
+		o.path = path
+	}
+}
+
+// OpenAPITitle with the title of the API, default "API".
+func OpenAPITitle(title string) OpenAPIOption {
+	return func(o *openAPIOptions) {
+		o.title = title
+	}
+}
+
+// OpenAPIVersion with the version of the API, default "1.0.0".
+func OpenAPIVersion(version string) OpenAPIOption {
+	return func(o *openAPIOptions) {
+		o.version = version
+	}
+}
+
+// OpenAPIDescription with the description of the API.
+func OpenAPIDescription(description string) OpenAPIOption {
+	return func(o *openAPIOptions) {
+		o.description = description
+	}
+}
+
+// SwaggerUI with a Swagger UI page of the document served at path.
+// The page loads the Swagger UI 5.17.14 assets embedded in the server by default,
+// they are served under path, such as path/5.17.14/swagger-ui-bundle.js.
+func SwaggerUI(path string) OpenAPIOption {
+	return func(o *openAPIOptions) {
+		o.swaggerUI = path
+	}
+}
+
+// SwaggerUIAssets with the bundle script and the stylesheet of the Swagger UI page,
+// to load them from another server, such as another version, instead of the embedded ones.
+func SwaggerUIAssets(bundle, stylesheet OpenAPIAsset) OpenAPIOption {
+	return func(o *openAPIOptions) {
+		o.swaggerJS, o.swaggerCSS = bundle, stylesheet
+	}
+}
+
+// Redoc with a Redoc page of the document served at path.
+// The page loads the Redoc 2.1.5 bundle from unpkg.com by default, without an integrity
+// hash, RedocAssets sets a self-hosted bundle or one with its hash.
+func Redoc(path string) OpenAPIOption {
+	return func(o *openAPIOptions) {
+		o.redoc = path
+	}
+}
+
+// RedocAssets with the standalone bundle of the Redoc page,
+// to self-host it or to check its integrity.
+func RedocAssets(bundle OpenAPIAsset) OpenAPIOption {
+	return func(o *openAPIOptions) {
+		o.redocJS = bundle
+	}
+}
+
+// OpenAPI with an OpenAPI 3.1 document of the routes registered by the routers,
+// the operations and schemas are taken from the route descriptions of Router.Describe.
+func OpenAPI(opts ...OpenAPIOption) ServerOption {
+	return func(s *Server) {
+		o := defaultOpenAPIOptions()
+		for _, opt := range opts {
+			opt(o)
+		}
+		s.openapi = o
+	}
+}
+
+func defaultOpenAPIOptions() *openAPIOptions {
+	return &openAPIOptions{
+		path:    defaultOpenAPIPath,
+		title:   "API",
+		version: "1.0.0",
+		redocJS: defaultRedocJS,
+	}
+}
+
+var openAPIPage = template.Must(template.New("openapi").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{.Title}}</title>
+  {{- if .Redoc}}
+</head>
+<body>
+  <redoc spec-url="{{.URL}}"></redoc>
+  <script src="{{.Script.URL}}"{{with .Script.Integrity}} integrity="{{.}}"{{end}} crossorigin="anonymous"></script>
+  {{- else}}
+  <link rel="stylesheet" href="{{.Stylesheet.URL}}"{{with .Stylesheet.Integrity}} integrity="{{.}}"{{end}} crossorigin="anonymous">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="{{.Script.URL}}"{{with .Script.Integrity}} integrity="{{.}}"{{end}} crossorigin="anonymous"></script>
+  <script>
+    window.onload = function () {
+      window.ui = SwaggerUIBundle({url: "{{.URL}}", dom_id: "#swagger-ui"});
+    };
+  </script>
+  {{- end}}
+</body>
+</html>
+`))
+
+func (s *Server) registerOpenAPI() {
+	o := s.openapi
+	if o == nil {
+		return
+	}
+	s.engine.GET(o.path, func(c *gin.Context) {
+		data, err := s.OpenAPIDocument()
+		if err != nil {
+			s.ene(c.Writer, c.Request, err)
+			return
+		}
+		c.Data(http.StatusOK, "application/json", data)
+	})
+	page := func(redoc bool, script, stylesheet OpenAPIAsset) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Header("Content-Type", "text/html; charset=utf-8")
+			c.Status(http.StatusOK)
+			_ = openAPIPage.Execute(c.Writer, struct {
+				Title      string
+				URL        string
+				Redoc      bool
+				Script     OpenAPIAsset
+				Stylesheet OpenAPIAsset
+			}{o.title, o.path, redoc, script, stylesheet})
+		}
+	}
+	if o.swaggerUI != "" {
+		script, stylesheet := o.swaggerJS, o.swaggerCSS
+		if script.URL == "" && stylesheet.URL == "" {
+			prefix := path.Join(o.swaggerUI, swaggerui.Version)
+			script.URL, stylesheet.URL = prefix+"/swagger-ui-bundle.js", prefix+"/swagger-ui.css"
+			s.engine.GET(script.URL, swaggerAsset("swagger-ui-bundle.js", "application/javascript"))
+			s.engine.GET(stylesheet.URL, swaggerAsset("swagger-ui.css", "text/css; charset=utf-8"))
+		}
+		s.engine.GET(o.swaggerUI, page(false, script, stylesheet))
+	}
+	if o.redoc != "" {
+		s.engine.GET(o.redoc, page(true, o.redocJS, OpenAPIAsset{}))
+	}
+}
+
+// swaggerAsset serves an embedded Swagger UI asset, gzipped when the request accepts it.
+// The assets are versioned by their path, they are cached as immutable.
+func swaggerAsset(name, contentType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		data, err := swaggerui.FS.ReadFile(name + ".gz")
+		if err != nil {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		h := c.Writer.Header()
+		h.Set("Content-Type", contentType)
+		h.Set("Cache-Control", "public, max-age=31536000, immutable")
+		addVary(h, "Accept-Encoding")
+		if negotiateEncoding(c.Request.Header.Values("Accept-Encoding"), []string{"gzip"}) == "gzip" {
+			h.Set("Content-Encoding", "gzip")
+			h.Set("Content-Length", strconv.Itoa(len(data)))
+			c.Status(http.StatusOK)
+			_, _ = c.Writer.Write(data)
+			return
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusOK)
+		_, _ = io.Copy(c.Writer, zr)
+	}
+}
+
+// OpenAPIDocument returns the OpenAPI 3.1 document of the routes registered by the routers.
+func (s *Server) OpenAPIDocument() ([]byte, error) {
+	o := s.openapi
+	if o == nil {
+		o = defaultOpenAPIOptions()
+	}
+	doc := &openAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info:    openAPIInfo{Title: o.title, Version: o.version, Description: o.description},
+		Paths:   make(map[string]map[string]*openAPIOperation),
+	}
+	b := &schemaBuilder{schemas: make(map[string]*jsonSchema)}
+	operationIDs := make(map[string]int)
+	err := s.walkPaths(func(method, routePath string) error {
+		tpl, ok := s.templates[routePath]
+		if !ok {
+			// only the routes of the routers are documented.
+			return nil
+		}
+		info, ok := s.routes[method+" "+routePath]
+		if !ok {
+			info = RouteInfo{Method: method, Path: routePath}
+		}
+		path, vars := openAPIPath(tpl)
+		// the variables of the path templates are named by the proto names of the fields,
+		// they are renamed to the JSON names of the query parameters and the schemas.
+		for i, v := range vars {
+			if name := jsonPath(info.Request, v); name != v {
+				path = strings.Replace(path, "{"+v+"}", "{"+name+"}", 1)
+				vars[i] = name
+			}
+		}
+		op := b.operation(info, vars)
+		if op.OperationID != "" {
+			// the additional bindings of an operation are numbered.
+			n := operationIDs[op.OperationID]
+			operationIDs[op.OperationID] = n + 1
+			if n > 0 {
+				op.OperationID += strconv.Itoa(n)
+			}
+		}
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*openAPIOperation)
+		}
+		doc.Paths[path][strings.ToLower(method)] = op
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	doc.Components.Schemas = b.schemas
+	return json.Marshal(doc)
+}
+
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*jsonSchema `json:"schemas,omitempty"`
+}
+
+type openAPIOperation struct {
+	OperationID string                      `json:"operationId,omitempty"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Parameters  []*openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name     string      `json:"name"`
+	In       string      `json:"in"`
+	Required bool        `json:"required,omitempty"`
+	Schema   *jsonSchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                    `json:"required,omitempty"`
+	Content  map[string]openAPIMedia `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                  `json:"description"`
+	Content     map[string]openAPIMedia `json:"content,omitempty"`
+}
+
+type openAPIMedia struct {
+	Schema *jsonSchema `json:"schema"`
+}
+
+type jsonSchema struct {
+	Ref                  string                 `json:"$ref,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+}
+
+var openAPIPathVar = regexp.MustCompile(`{([^}=]+)(=[^}]*)?}|[:*]([A-Za-z_][^/]*)|\*\*?`)
+
+// openAPIPath converts a path template to an OpenAPI path, and returns its variables.
+// The segments templates are dropped: /v1/{name=messages/*} is /v1/{name}, and the
+// anonymous wildcards are numbered: /v1/*/messages is /v1/{$0}/messages.
+func openAPIPath(tpl *pathtemplate.Template) (string, []string) {
+	raw := tpl.Template()
+	if tpl.Verb() != "" {
+		raw = strings.TrimSuffix(raw, ":"+tpl.Verb())
+	}
+	var (
+		sb   strings.Builder
+		vars []string
+		anon int
+		last int
+	)
+	for _, m := range openAPIPathVar.FindAllStringSubmatchIndex(raw, -1) {
+		name := ""
+		switch {
+		case m[2] >= 0:
+			name = raw[m[2]:m[3]]
+		case m[6] >= 0:
+			name = raw[m[6]:m[7]]
+		case m[0] > 0 && raw[m[0]-1] == '/' && (m[1] == len(raw) || raw[m[1]] == '/'):
+			name = "$" + strconv.Itoa(anon)
+			anon++
+		default:
+			continue
+		}
+		sb.WriteString(raw[last:m[0]])
+		sb.WriteString("{" + name + "}")
+		vars = append(vars, name)
+		last = m[1]
+	}
+	sb.WriteString(raw[last:])
+	path := sb.String()
+	if tpl.Verb() != "" {
+		path += ":" + tpl.Verb()
+	}
+	return path, vars
+}
+
+func jsonContent(schema *jsonSchema) map[string]openAPIMedia {
+	return map[string]openAPIMedia{"application/json": {Schema: schema}}
+}
+
+// schemaBuilder builds the schemas of the request and reply types,
+// the named types are added to the components.
+type schemaBuilder struct {
+	schemas map[string]*jsonSchema
+}
+
+func (b *schemaBuilder) operation(info RouteInfo, vars []string) *openAPIOperation {
+	op := &openAPIOperation{Responses: make(map[string]*openAPIResponse)}
+	if info.Operation != "" {
+		// /helloworld.Greeter/SayHello is the Greeter_SayHello operation of the helloworld.Greeter tag.
+		service, method := splitOperation(info.Operation)
+		op.OperationID = method
+		if service != "" {
+			op.Tags = []string{service}
+			op.OperationID = service[strings.LastIndex(service, ".")+1:] + "_" + method
+		}
+	}
+	pathVars := make(map[string]bool, len(vars))
+	for _, v := range vars {
+		pathVars[v] = true
+		schema := b.fieldPath(info.Request, v)
+		if schema == nil {
+			schema = &jsonSchema{Type: "string"}
+		}
+		op.Parameters = append(op.Parameters, &openAPIParameter{Name: v, In: "path", Required: true, Schema: schema})
+	}
+	if info.Request != nil {
+		if info.Body != "*" {
+			body := jsonPath(info.Request, info.Body)
+			for _, p := range b.queryParams(info.Request) {
+				if !pathVars[p.Name] && (body == "" || p.Name != body && !strings.HasPrefix(p.Name, body+".")) {
+					op.Parameters = append(op.Parameters, p)
+				}
+			}
+		}
+		switch info.Body {
+		case "":
+		case "*":
+			op.RequestBody = &openAPIRequestBody{Required: true, Content: jsonContent(b.valueSchema(info.Request))}
+		default:
+			if schema := b.fieldPath(info.Request, info.Body); schema != nil {
+				op.RequestBody = &openAPIRequestBody{Required: true, Content: jsonContent(schema)}
+			}
+		}
+	}
+	res := &openAPIResponse{Description: "OK"}
+	if info.Reply != nil {
+		schema := b.valueSchema(info.Reply)
+		if info.ResponseBody != "" {
+			schema = b.fieldPath(info.Reply, info.ResponseBody)
+		}
+		if schema != nil {
+			res.Content = jsonContent(schema)
+		}
+	}
+	op.Responses["200"] = res
+	op.Responses["default"] = &openAPIResponse{
+		Description: "Error",
+		Content:     jsonContent(b.valueSchema((*errors.Status)(nil))),
+	}
+	return op
+}
+
+func splitOperation(operation string) (string, string) {
+	parts := strings.Split(strings.TrimPrefix(operation, "/"), "/")
+	if len(parts) != 2 {
+		return "", operation
+	}
+	return parts[0], parts[1]
+}
+
+var protoMessageType = reflect.TypeOf((*proto.Message)(nil)).Elem()
+
+// valueSchema returns the schema of the type of v, the descriptor of the
+// proto messages is taken from v as the dynamic messages have no Go type.
+func (b *schemaBuilder) valueSchema(v interface{}) *jsonSchema {
+	if m, ok := v.(proto.Message); ok {
+		return b.messageSchema(m.ProtoReflect().Descriptor())
+	}
+	return b.schema(reflect.TypeOf(v))
+}
+
+// schema returns the schema of a type, a reference for the named types.
+func (b *schemaBuilder) schema(t reflect.Type) *jsonSchema {
+	if t.Implements(protoMessageType) {
+		return b.messageSchema(reflect.Zero(t).Interface().(proto.Message).ProtoReflect().Descriptor())
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return &jsonSchema{Type: "string", Format: "date-time"}
+	case reflect.TypeOf(time.Duration(0)):
+		return &jsonSchema{Type: "integer", Format: "int64"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &jsonSchema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &jsonSchema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &jsonSchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &jsonSchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &jsonSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &jsonSchema{Type: "string", Format: "byte"}
+		}
+		return &jsonSchema{Type: "array", Items: b.schema(t.Elem())}
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: b.schema(t.Elem())}
+	case reflect.Struct:
+		name := t.String()
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		if _, ok := b.schemas[name]; !ok {
+			// registered before the fields are built, for the recursive types.
+			b.schemas[name] = &jsonSchema{}
+			*b.schemas[name] = *b.structSchema(t)
+		}
+		return &jsonSchema{Ref: "#/components/schemas/" + name}
+	}
+	return &jsonSchema{}
+}
+
+func (b *schemaBuilder) structSchema(t reflect.Type) *jsonSchema {
+	s := &jsonSchema{Type: "object", Properties: make(map[string]*jsonSchema)}
+	for _, f := range structFields(t) {
+		s.Properties[f.name] = b.schema(f.typ)
+	}
+	return s
+}
+
+type structField struct {
+	name string
+	typ  reflect.Type
+}
+
+// structFields returns the JSON fields of a struct, the embedded structs are flattened.
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			fields = append(fields, structFields(ft)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, structField{name: name, typ: f.Type})
+	}
+	return fields
+}
+
+// messageSchema returns a reference to the schema of a proto message, as encoded by protojson.
+func (b *schemaBuilder) messageSchema(md protoreflect.MessageDescriptor) *jsonSchema {
+	if s := wellKnownSchema(md); s != nil {
+		return s
+	}
+	name := string(md.FullName())
+	if _, ok := b.schemas[name]; !ok {
+		s := &jsonSchema{Type: "object", Properties: make(map[string]*jsonSchema)}
+		b.schemas[name] = s
+		fields := md.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			fd := fields.Get(i)
+			s.Properties[fd.JSONName()] = b.fieldSchema(fd)
+		}
+	}
+	return &jsonSchema{Ref: "#/components/schemas/" + name}
+}
+
+func (b *schemaBuilder) fieldSchema(fd protoreflect.FieldDescriptor) *jsonSchema {
+	switch {
+	case fd.IsMap():
+		return &jsonSchema{Type: "object", AdditionalProperties: b.singularSchema(fd.MapValue())}
+	case fd.IsList():
+		return &jsonSchema{Type: "array", Items: b.singularSchema(fd)}
+	}
+	return b.singularSchema(fd)
+}
+
+func (b *schemaBuilder) singularSchema(fd protoreflect.FieldDescriptor) *jsonSchema {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return &jsonSchema{Type: "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return &jsonSchema{Type: "integer", Format: "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return &jsonSchema{Type: "integer", Format: "uint32"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		// protojson encodes the 64-bit integers as strings.
+		return &jsonSchema{Type: "string", Format: "int64"}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return &jsonSchema{Type: "string", Format: "uint64"}
+	case protoreflect.FloatKind:
+		return &jsonSchema{Type: "number", Format: "float"}
+	case protoreflect.DoubleKind:
+		return &jsonSchema{Type: "number", Format: "double"}
+	case protoreflect.StringKind:
+		return &jsonSchema{Type: "string"}
+	case protoreflect.BytesKind:
+		return &jsonSchema{Type: "string", Format: "byte"}
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		s := &jsonSchema{Type: "string", Enum: make([]string, 0, values.Len())}
+		for i := 0; i < values.Len(); i++ {
+			s.Enum = append(s.Enum, string(values.Get(i).Name()))
+		}
+		return s
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return b.messageSchema(fd.Message())
+	}
+	return &jsonSchema{}
+}
+
+// wellKnownSchema returns the schema of the well-known types, which protojson encodes specially.
+func wellKnownSchema(md protoreflect.MessageDescriptor) *jsonSchema {
+	switch md.FullName() {
+	case "google.protobuf.Timestamp":
+		return &jsonSchema{Type: "string", Format: "date-time"}
+	case "google.protobuf.Duration", "google.protobuf.FieldMask":
+		return &jsonSchema{Type: "string"}
+	case "google.protobuf.Struct", "google.protobuf.Any", "google.protobuf.Empty":
+		return &jsonSchema{Type: "object"}
+	case "google.protobuf.ListValue":
+		return &jsonSchema{Type: "array", Items: &jsonSchema{}}
+	case "google.protobuf.Value":
+		return &jsonSchema{}
+	case "google.protobuf.StringValue":
+		return &jsonSchema{Type: "string"}
+	case "google.protobuf.BytesValue":
+		return &jsonSchema{Type: "string", Format: "byte"}
+	case "google.protobuf.BoolValue":
+		return &jsonSchema{Type: "boolean"}
+	case "google.protobuf.Int32Value", "google.protobuf.UInt32Value":
+		return &jsonSchema{Type: "integer", Format: "int32"}
+	case "google.protobuf.Int64Value", "google.protobuf.UInt64Value":
+		return &jsonSchema{Type: "string", Format: "int64"}
+	case "google.protobuf.FloatValue", "google.protobuf.DoubleValue":
+		return &jsonSchema{Type: "number"}
+	}
+	return nil
+}
+
+// fieldPath returns the schema of the field of v at path, such as "name" or "user.name",
+// the fields are matched by their proto or JSON names.
+func (b *schemaBuilder) fieldPath(v interface{}, path string) *jsonSchema {
+	if v == nil {
+		return nil
+	}
+	if m, ok := v.(proto.Message); ok {
+		md := m.ProtoReflect().Descriptor()
+		parts := strings.Split(path, ".")
+		for i, part := range parts {
+			fd := md.Fields().ByName(protoreflect.Name(part))
+			if fd == nil {
+				fd = md.Fields().ByJSONName(part)
+			}
+			if fd == nil {
+				return nil
+			}
+			if i == len(parts)-1 {
+				return b.fieldSchema(fd)
+			}
+			if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+				return nil
+			}
+			md = fd.Message()
+		}
+		return nil
+	}
+	t := reflect.TypeOf(v)
+	for _, part := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return nil
+		}
+		var found bool
+		for _, f := range structFields(t) {
+			if f.name == part {
+				t, found = f.typ, true
+				break
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+	return b.schema(t)
+}
+
+// jsonPath returns the path of the field of v at path, such as "user.name", with the JSON
+// names of the proto fields. The path is returned as is when the field is not found, and
+// for the Go structs, whose fields have a single name.
+func jsonPath(v interface{}, path string) string {
+	m, ok := v.(proto.Message)
+	if !ok || path == "" || path == "*" {
+		return path
+	}
+	md := m.ProtoReflect().Descriptor()
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		if md == nil {
+			return path
+		}
+		fd := md.Fields().ByName(protoreflect.Name(part))
+		if fd == nil {
+			fd = md.Fields().ByJSONName(part)
+		}
+		if fd == nil {
+			return path
+		}
+		parts[i] = fd.JSONName()
+		md = fd.Message()
+	}
+	return strings.Join(parts, ".")
+}
+
+// maxQueryDepth bounds the nested fields of the query parameters, for the recursive types.
+const maxQueryDepth = 3
+
+// queryParams returns the query parameters of a request, the nested fields are named by their path.
+func (b *schemaBuilder) queryParams(v interface{}) []*openAPIParameter {
+	var params []*openAPIParameter
+	if m, ok := v.(proto.Message); ok {
+		b.protoQueryParams(m.ProtoReflect().Descriptor(), "", 0, &params)
+	} else {
+		b.structQueryParams(reflect.TypeOf(v), "", 0, &params)
+	}
+	return params
+}
+
+func (b *schemaBuilder) protoQueryParams(md protoreflect.MessageDescriptor, prefix string, depth int, params *[]*openAPIParameter) {
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		name := prefix + fd.JSONName()
+		switch {
+		case fd.IsMap():
+			continue
+		case fd.Message() != nil && wellKnownSchema(fd.Message()) == nil:
+			if !fd.IsList() && depth < maxQueryDepth {
+				b.protoQueryParams(fd.Message(), name+".", depth+1, params)
+			}
+			continue
+		}
+		*params = append(*params, &openAPIParameter{Name: name, In: "query", Schema: b.fieldSchema(fd)})
+	}
+}
+
+func (b *schemaBuilder) structQueryParams(t reflect.Type, prefix string, depth int, params *[]*openAPIParameter) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for _, f := range structFields(t) {
+		name := prefix + f.name
+		ft := f.typ
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		switch {
+		case ft.Kind() == reflect.Map:
+			continue
+		case ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}):
+			if depth < maxQueryDepth {
+				b.structQueryParams(ft, name+".", depth+1, params)
+			}
+			continue
+		}
+		*params = append(*params, &openAPIParameter{Name: name, In: "query", Schema: b.schema(f.typ)})
+	}
+}
@@ -0,0 +1,278 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JellyTony/zeus/internal/pathtemplate"
+	"github.com/JellyTony/zeus/internal/testdata/binding"
+)
+
+func TestOpenAPIPath(t *testing.T) {
+	tests := []struct {
+		tpl  string
+		path string
+		vars []string
+	}{
+		{"/helloworld/{name}", "/helloworld/{name}", []string{"name"}},
+		{"/v1/{name=messages/*}", "/v1/{name}", []string{"name"}},
+		{"/v1/files/{path=**}", "/v1/files/{path}", []string{"path"}},
+		{"/users/:id/files/*path", "/users/{id}/files/{path}", []string{"id", "path"}},
+		{"/v1/users", "/v1/users", nil},
+		{"/v1/{name=operations/*}:cancel", "/v1/{name}:cancel", []string{"name"}},
+		{"/v1/users:batchGet", "/v1/users:batchGet", nil},
+		{"/v1/*/files/**", "/v1/{$0}/files/{$1}", []string{"$0", "$1"}},
+		{"/v1/*:batchGet", "/v1/{$0}:batchGet", []string{"$0"}},
+	}
+	for _, test := range tests {
+		path, vars := openAPIPath(pathtemplate.MustCompile(test.tpl))
+		if path != test.path || !reflect.DeepEqual(vars, test.vars) {
+			t.Errorf("%s: expected %s %v got %s %v", test.tpl, test.path, test.vars, path, vars)
+		}
+	}
+}
+
+type openAPIAddress struct {
+	City string `json:"city"`
+}
+
+type openAPIUser struct {
+	ID      int64             `json:"id"`
+	Name    string            `json:"name,omitempty"`
+	Tags    []string          `json:"tags"`
+	Labels  map[string]string `json:"labels"`
+	Created time.Time         `json:"created"`
+	Address *openAPIAddress   `json:"address"`
+	secret  string
+}
+
+type getUserRequest struct {
+	ID     int64    `json:"id"`
+	Fields []string `json:"fields"`
+}
+
+// lookup returns the value of the JSON document at the keys.
+func lookup(v interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+func openAPIDocumentOf(t *testing.T, srv *Server, path string) map[string]interface{} {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, req)
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected %d got %d", http.StatusOK, res.Code)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(res.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func parameterNames(op interface{}) map[string]string {
+	names := make(map[string]string)
+	params, _ := lookup(op, "parameters").([]interface{})
+	for _, p := range params {
+		names[lookup(p, "name").(string)] = lookup(p, "in").(string)
+	}
+	return names
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	srv := NewServer(OpenAPI(OpenAPITitle("Users"), OpenAPIVersion("2.0.0")))
+	r := srv.Route("/")
+	r.GET("/users/{id}", func(ctx Context) error { return nil })
+	r.Describe(http.MethodGet, "/users/{id}", RouteInfo{
+		Operation: "/test.Users/Get",
+		Request:   (*getUserRequest)(nil),
+		Reply:     (*openAPIUser)(nil),
+	})
+	r.POST("/users", func(ctx Context) error { return nil })
+	r.Describe(http.MethodPost, "/users", RouteInfo{
+		Operation: "/test.Users/Create",
+		Request:   (*openAPIUser)(nil),
+		Reply:     (*openAPIUser)(nil),
+		Body:      "*",
+	})
+	r.GET("/undescribed", func(ctx Context) error { return nil })
+	r.GET("/v1/hello/{opt_string}/{sub.name}", func(ctx Context) error { return nil })
+	r.Describe(http.MethodGet, "/v1/hello/{opt_string}/{sub.name}", RouteInfo{Request: (*binding.HelloRequest)(nil)})
+	r.PATCH("/v1/hello/{opt_string}", func(ctx Context) error { return nil })
+	r.Describe(http.MethodPatch, "/v1/hello/{opt_string}", RouteInfo{Request: (*binding.HelloRequest)(nil), Body: "update_mask"})
+	srv.RegisterService(&echoServiceDesc, echoService{})
+
+	doc := openAPIDocumentOf(t, srv, "/openapi.json")
+	tests := []struct {
+		keys []string
+		want interface{}
+	}{
+		{[]string{"openapi"}, "3.1.0"},
+		{[]string{"info", "title"}, "Users"},
+		{[]string{"info", "version"}, "2.0.0"},
+		{[]string{"paths", "/users/{id}", "get", "operationId"}, "Users_Get"},
+		{[]string{"paths", "/users/{id}", "get", "tags"}, []interface{}{"test.Users"}},
+		{[]string{"paths", "/users/{id}", "get", "responses", "200", "content", "application/json", "schema", "$ref"}, "#/components/schemas/http.openAPIUser"},
+		{[]string{"paths", "/users/{id}", "get", "responses", "default", "content", "application/json", "schema", "$ref"}, "#/components/schemas/errors.Status"},
+		{[]string{"paths", "/users", "post", "requestBody", "content", "application/json", "schema", "$ref"}, "#/components/schemas/http.openAPIUser"},
+		{[]string{"paths", "/undescribed", "get", "responses", "200", "description"}, "OK"},
+		{[]string{"components", "schemas", "http.openAPIUser", "properties", "id", "format"}, "int64"},
+		{[]string{"components", "schemas", "http.openAPIUser", "properties", "tags", "items", "type"}, "string"},
+		{[]string{"components", "schemas", "http.openAPIUser", "properties", "labels", "additionalProperties", "type"}, "string"},
+		{[]string{"components", "schemas", "http.openAPIUser", "properties", "created", "format"}, "date-time"},
+		{[]string{"components", "schemas", "http.openAPIUser", "properties", "address", "$ref"}, "#/components/schemas/http.openAPIAddress"},
+		{[]string{"components", "schemas", "http.openAPIUser", "properties", "secret"}, nil},
+		{[]string{"components", "schemas", "http.openAPIAddress", "properties", "city", "type"}, "string"},
+		// the routes of the service are described by RegisterService.
+		{[]string{"paths", "/v1/messages", "post", "operationId"}, "Echo_Create"},
+		{[]string{"paths", "/v1/messages", "post", "requestBody", "content", "application/json", "schema", "$ref"}, "#/components/schemas/zeus.test.Message"},
+		{[]string{"paths", "/v1/messages/{id}", "patch", "requestBody", "content", "application/json", "schema", "$ref"}, "#/components/schemas/zeus.test.Sub"},
+		{[]string{"paths", "/v1/messages/{id}", "patch", "responses", "200", "content", "application/json", "schema", "$ref"}, "#/components/schemas/zeus.test.Sub"},
+		{[]string{"paths", "/v1/messages/{id}/tags", "put", "requestBody", "content", "application/json", "schema", "items", "type"}, "string"},
+		{[]string{"paths", "/v1/messages/{id}/tags", "put", "responses", "200", "content", "application/json", "schema", "type"}, "array"},
+		{[]string{"components", "schemas", "zeus.test.Message", "properties", "sub", "$ref"}, "#/components/schemas/zeus.test.Sub"},
+	}
+	for _, test := range tests {
+		if got := lookup(doc, test.keys...); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: expected %v got %v", strings.Join(test.keys, "."), test.want, got)
+		}
+	}
+	if _, ok := lookup(doc, "paths", defaultOpenAPIPath).(map[string]interface{}); ok {
+		t.Errorf("expected the engine routes to be undocumented")
+	}
+
+	params := []struct {
+		keys []string
+		want map[string]string
+	}{
+		{[]string{"/users/{id}", "get"}, map[string]string{"id": "path", "fields": "query"}},
+		{[]string{"/users", "post"}, map[string]string{}},
+		{[]string{"/v1/messages/{id}", "get"}, map[string]string{"id": "path", "name": "query", "sub.value": "query", "tags": "query"}},
+		{[]string{"/v1/messages/{id}", "patch"}, map[string]string{"id": "path", "name": "query", "tags": "query"}},
+		// the path variables and the body are named by the JSON names of the fields, as the query parameters are.
+		{[]string{"/v1/hello/{optString}/{sub.naming}", "get"}, map[string]string{
+			"optString": "path", "sub.naming": "path", "name": "query", "updateMask": "query",
+			"optInt32": "query", "optInt64": "query", "subField.naming": "query", "test_repeated": "query",
+		}},
+		{[]string{"/v1/hello/{optString}", "patch"}, map[string]string{
+			"optString": "path", "name": "query", "sub.naming": "query",
+			"optInt32": "query", "optInt64": "query", "subField.naming": "query", "test_repeated": "query",
+		}},
+	}
+	for _, test := range params {
+		if got := parameterNames(lookup(doc["paths"], test.keys...)); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: expected %v got %v", strings.Join(test.keys, " "), test.want, got)
+		}
+	}
+
+	// the additional bindings of an operation are numbered.
+	ids := map[interface{}]bool{
+		lookup(doc, "paths", "/v1/messages/{id}", "get", "operationId"):              true,
+		lookup(doc, "paths", "/v1/users/{name}/messages/{id}", "get", "operationId"): true,
+	}
+	if !ids["Echo_Get"] || !ids["Echo_Get1"] {
+		t.Errorf("expected %v got %v", []string{"Echo_Get", "Echo_Get1"}, ids)
+	}
+}
+
+func TestOpenAPIWalkRoute(t *testing.T) {
+	srv := NewServer()
+	srv.RegisterService(&echoServiceDesc, echoService{})
+	var info RouteInfo
+	_ = srv.WalkRoute(func(route RouteInfo) error {
+		if route.Method == http.MethodPatch && route.Path == "/v1/messages/:id" {
+			info = route
+		}
+		return nil
+	})
+	if info.Operation != "/zeus.test.Echo/Update" || info.Body != "sub" || info.ResponseBody != "sub" || info.Request == nil {
+		t.Errorf("unexpected route description %+v", info)
+	}
+}
+
+func TestOpenAPIPages(t *testing.T) {
+	srv := NewServer(OpenAPI(OpenAPIPath("/docs/openapi.json"), SwaggerUI("/docs/swagger"), Redoc("/docs/redoc")))
+	if doc := openAPIDocumentOf(t, srv, "/docs/openapi.json"); lookup(doc, "info", "title") != "API" {
+		t.Errorf("expected %s got %v", "API", lookup(doc, "info", "title"))
+	}
+	tests := []struct {
+		path string
+		code int
+		want string
+	}{
+		{"/docs/swagger", 200, `<script src="/docs/swagger/5.17.14/swagger-ui-bundle.js" crossorigin="anonymous">`},
+		{"/docs/swagger/5.17.14/swagger-ui.css", 200, ".swagger-ui{"},
+		{"/docs/swagger/5.17.14/swagger-ui-bundle.js", 200, `PACKAGE_VERSION:"5.17.14"`},
+		{"/docs/redoc", 200, `<redoc spec-url="/docs/openapi.json">`},
+		{"/openapi.json", 404, ""},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, req)
+		if res.Code != test.code {
+			t.Errorf("%s: expected %d got %d", test.path, test.code, res.Code)
+		}
+		if !strings.Contains(res.Body.String(), test.want) {
+			t.Errorf("%s: expected %s in %s", test.path, test.want, res.Body.String())
+		}
+	}
+
+	// the embedded assets are sent gzipped when the request accepts it.
+	req := httptest.NewRequest(http.MethodGet, "/docs/swagger/5.17.14/swagger-ui.css", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, req)
+	if got := res.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("expected %s got %s", "gzip", got)
+	}
+	if zr, err := gzip.NewReader(res.Body); err != nil {
+		t.Error(err)
+	} else if data, _ := io.ReadAll(zr); !bytes.HasPrefix(data, []byte(".swagger-ui{")) {
+		t.Errorf("unexpected stylesheet %.40s", data)
+	}
+
+	// the other assets are loaded with their integrity hashes when they are set.
+	srv = NewServer(OpenAPI(SwaggerUI("/swagger"), Redoc("/redoc"),
+		SwaggerUIAssets(OpenAPIAsset{URL: "/assets/swagger-ui-bundle.js", Integrity: "sha384-js"}, OpenAPIAsset{URL: "/assets/swagger-ui.css", Integrity: "sha384-css"}),
+	))
+	pages := map[string][]string{
+		"/swagger": {
+			`<script src="/assets/swagger-ui-bundle.js" integrity="sha384-js" crossorigin="anonymous">`,
+			`<link rel="stylesheet" href="/assets/swagger-ui.css" integrity="sha384-css" crossorigin="anonymous">`,
+		},
+		"/redoc": {`<script src="https://unpkg.com/redoc@2.1.5/bundles/redoc.standalone.js" crossorigin="anonymous">`},
+	}
+	for path, want := range pages {
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, httptest.NewRequest(http.MethodGet, path, nil))
+		for _, w := range want {
+			if !strings.Contains(res.Body.String(), w) {
+				t.Errorf("%s: expected %s in %s", path, w, res.Body.String())
+			}
+		}
+	}
+
+	// the document is not served without the OpenAPI option.
+	req = httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	res = httptest.NewRecorder()
+	NewServer().ServeHTTP(res, req)
+	if res.Code != http.StatusNotFound {
+		t.Errorf("expected %d got %d", http.StatusNotFound, res.Code)
+	}
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Peer is the identity of the remote peer, taken from its verified TLS client certificate.
+type Peer struct {
+	// Subject is the distinguished name of the certificate subject.
+	Subject string
+	// CommonName is the common name of the certificate subject.
+	CommonName string
+	// DNSNames is the DNS subject alternative names.
+	DNSNames []string
+	// URIs is the URI subject alternative names.
+	URIs []string
+	// SPIFFEID is the first spiffe:// URI subject alternative name.
+	SPIFFEID string
+	// Certificate is the leaf certificate of the peer.
+	Certificate *x509.Certificate
+}
+
+// Identities returns the identities of the peer: the SPIFFE ID, the URI and DNS
+// subject alternative names, and the common name.
+func (p *Peer) Identities() []string {
+	ids := make([]string, 0, len(p.URIs)+len(p.DNSNames)+1)
+	ids = append(ids, p.URIs...)
+	ids = append(ids, p.DNSNames...)
+	if p.CommonName != "" {
+		ids = append(ids, p.CommonName)
+	}
+	return ids
+}
+
+// newPeer returns the peer of the leaf of the first verified chain. The client certificates
+// are not verified with tls.RequestClientCert or tls.RequireAnyClientCert, so they give no peer.
+func newPeer(state *tls.ConnectionState) *Peer {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	cert := state.VerifiedChains[0][0]
+	p := &Peer{
+		Subject:     cert.Subject.String(),
+		CommonName:  cert.Subject.CommonName,
+		DNSNames:    cert.DNSNames,
+		Certificate: cert,
+	}
+	for _, u := range cert.URIs {
+		uri := u.String()
+		p.URIs = append(p.URIs, uri)
+		if p.SPIFFEID == "" && strings.EqualFold(u.Scheme, "spiffe") {
+			p.SPIFFEID = uri
+		}
+	}
+	return p
+}
+
+// Peer returns the identity of the remote peer, it is nil without a verified client certificate.
+func (tr *Transport) Peer() *Peer {
+	return tr.peer
+}
+
+// PeerFromContext returns the identity of the remote peer of the server request in ctx.
+func PeerFromContext(ctx context.Context) (*Peer, bool) {
+	tr, ok := transport.FromServerContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	ht, ok := tr.(*Transport)
+	if !ok || ht.peer == nil {
+		return nil, false
+	}
+	return ht.peer, true
+}
@@ -0,0 +1,174 @@
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func newTestCertificate(t *testing.T, cn string, uris ...string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn, Organization: []string{"zeus"}},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	for _, uri := range uris {
+		u, err := url.Parse(uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tpl.URIs = append(tpl.URIs, u)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestNewPeer(t *testing.T) {
+	if p := newPeer(nil); p != nil {
+		t.Errorf("expected nil got %v", p)
+	}
+	if p := newPeer(&tls.ConnectionState{}); p != nil {
+		t.Errorf("expected nil got %v", p)
+	}
+	cert := newTestCertificate(t, "greeter", "https://example.com/greeter", "spiffe://cluster.local/ns/default/sa/greeter")
+	// the certificate is not verified.
+	if p := newPeer(&tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert.Leaf}}); p != nil {
+		t.Errorf("expected nil got %v", p)
+	}
+	p := newPeer(&tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert.Leaf},
+		VerifiedChains:   [][]*x509.Certificate{{cert.Leaf}},
+	})
+	if p.CommonName != "greeter" {
+		t.Errorf("expected %s got %s", "greeter", p.CommonName)
+	}
+	if p.Subject != "CN=greeter,O=zeus" {
+		t.Errorf("expected %s got %s", "CN=greeter,O=zeus", p.Subject)
+	}
+	if p.SPIFFEID != "spiffe://cluster.local/ns/default/sa/greeter" {
+		t.Errorf("expected %s got %s", "spiffe://cluster.local/ns/default/sa/greeter", p.SPIFFEID)
+	}
+	want := []string{"https://example.com/greeter", "spiffe://cluster.local/ns/default/sa/greeter", "localhost", "greeter"}
+	if !reflect.DeepEqual(want, p.Identities()) {
+		t.Errorf("expected %v got %v", want, p.Identities())
+	}
+}
+
+func TestPeerFromContext(t *testing.T) {
+	if _, ok := PeerFromContext(context.Background()); ok {
+		t.Error("expected no peer")
+	}
+	ctx := transport.NewServerContext(context.Background(), &Transport{})
+	if _, ok := PeerFromContext(ctx); ok {
+		t.Error("expected no peer")
+	}
+	p := &Peer{CommonName: "greeter"}
+	ctx = transport.NewServerContext(context.Background(), &Transport{peer: p})
+	if v, ok := PeerFromContext(ctx); !ok || v != p {
+		t.Errorf("expected %v got %v", p, v)
+	}
+}
+
+func TestServerPeer(t *testing.T) {
+	ctx := context.Background()
+	serverCert := newTestCertificate(t, "server")
+	clientCert := newTestCertificate(t, "client", "spiffe://cluster.local/ns/default/sa/client")
+	// the self-signed certificate is presented with the identity of the client.
+	forgedCert := newTestCertificate(t, "client", "spiffe://cluster.local/ns/default/sa/client")
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert.Leaf)
+
+	tests := []struct {
+		name       string
+		clientAuth tls.ClientAuthType
+		cert       tls.Certificate
+		code       int
+		spiffeID   string
+	}{
+		{"verified", tls.VerifyClientCertIfGiven, clientCert, 200, "spiffe://cluster.local/ns/default/sa/client"},
+		{"unverified", tls.RequireAnyClientCert, clientCert, 401, ""},
+		{"forged", tls.RequireAnyClientCert, forgedCert, 401, ""},
+	}
+	for _, test := range tests {
+		srv := NewServer(
+			TLSConfig(&tls.Config{
+				Certificates: []tls.Certificate{serverCert},
+				ClientAuth:   test.clientAuth,
+				ClientCAs:    clientCAs,
+				MinVersion:   tls.VersionTLS12,
+			}),
+			Middleware(Authorize(map[string][]string{"/peer": {"*"}})),
+		)
+		srv.Route("/").GET("/peer", func(ctx Context) error {
+			p, ok := PeerFromContext(ctx)
+			if !ok {
+				return ctx.String(200, "")
+			}
+			return ctx.String(200, p.SPIFFEID)
+		})
+		e, err := srv.Endpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		done := make(chan error, 1)
+		go func() {
+			done <- srv.Start(ctx)
+		}()
+
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			Certificates:       []tls.Certificate{test.cert},
+			InsecureSkipVerify: true, //nolint:gosec
+		}}}
+		resp, err := client.Get(e.String() + "/peer")
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != test.code {
+			t.Errorf("%s: expected %d got %d", test.name, test.code, resp.StatusCode)
+		}
+		if test.code == 200 && string(data) != test.spiffeID {
+			t.Errorf("%s: expected %s got %s", test.name, test.spiffeID, data)
+		}
+		client.CloseIdleConnections()
+		if err := srv.Stop(ctx); err != nil {
+			t.Error(err)
+		}
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// ProblemContentType is the media type of the RFC 9457 problem details.
+const ProblemContentType = "application/problem+json"
+
+// the members of the problem details, the metadata of the errors are extension members.
+var problemMembers = map[string]bool{
+	"type":     true,
+	"title":    true,
+	"status":   true,
+	"detail":   true,
+	"instance": true,
+	"reason":   true,
+	"details":  true,
+}
+
+// ProblemErrorEncoder encodes the error as RFC 9457 problem details when the request
+// accepts application/problem+json, and with DefaultErrorEncoder otherwise.
+// The message of the error is the detail of the problem, its reason and metadata
+// are extension members, and the details attached by WithDetails are its details member.
+func ProblemErrorEncoder(w http.ResponseWriter, r *http.Request, err error) {
+	if !acceptsProblem(r) {
+		DefaultErrorEncoder(w, r, err)
+		return
+	}
+	se := errors.FromError(err)
+	problem := map[string]interface{}{
+		"type":   "about:blank",
+		"title":  http.StatusText(int(se.Code)),
+		"status": se.Code,
+	}
+	if se.Message != "" {
+		problem["detail"] = se.Message
+	}
+	if r.URL != nil && r.URL.Path != "" {
+		problem["instance"] = r.URL.Path
+	}
+	if se.Reason != "" {
+		problem["reason"] = se.Reason
+	}
+	for k, v := range se.Metadata {
+		if !problemMembers[k] {
+			problem[k] = v
+		}
+	}
+	if details := ErrorDetails(err); len(details) > 0 {
+		raws, err := detailsJSON(details)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		members := make([]json.RawMessage, 0, len(raws))
+		for _, raw := range raws {
+			members = append(members, raw)
+		}
+		problem["details"] = members
+	}
+	body, err := json.Marshal(problem)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(int(se.Code))
+	_, _ = w.Write(body)
+}
+
+// ProblemErrorDecoder decodes the RFC 9457 problem details of the responses of
+// application/problem+json, and the other responses with DefaultErrorDecoder.
+// The string extension members of the problem are the metadata of the error.
+func ProblemErrorDecoder(ctx context.Context, res *http.Response) error {
+	if res.StatusCode >= 200 && res.StatusCode <= 299 {
+		return nil
+	}
+	if mt, _, err := mime.ParseMediaType(res.Header.Get("Content-Type")); err != nil || mt != ProblemContentType {
+		return DefaultErrorDecoder(ctx, res)
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	var problem map[string]json.RawMessage
+	if err == nil {
+		err = json.Unmarshal(data, &problem)
+	}
+	if err != nil {
+		return errors.Newf(res.StatusCode, errors.UnknownReason, "").WithCause(err)
+	}
+	var detail, reason string
+	_ = json.Unmarshal(problem["detail"], &detail)
+	_ = json.Unmarshal(problem["reason"], &reason)
+	e := errors.New(res.StatusCode, reason, detail)
+	for k, raw := range problem {
+		var v string
+		if !problemMembers[k] && json.Unmarshal(raw, &v) == nil {
+			if e.Metadata == nil {
+				e.Metadata = make(map[string]string)
+			}
+			e.Metadata[k] = v
+		}
+	}
+	if details := unmarshalDetails(encoding.GetCodec("json"), data); len(details) > 0 {
+		return WithDetails(e, details...)
+	}
+	return e
+}
+
+// acceptsProblem reports whether the request accepts application/problem+json by name,
+// at least as much as the media types of DefaultErrorEncoder.
+func acceptsProblem(r *http.Request) bool {
+	ranges := parseMediaRanges(r.Header.Values("Accept"))
+	q, named := quality(ranges, ProblemContentType)
+	if !named || q == 0 {
+		return false
+	}
+	for _, offer := range defaultOffers(ranges) {
+		if oq, _ := quality(ranges, offer); oq > q {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestProblemErrorEncoder(t *testing.T) {
+	violations := &errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "name", Description: "required"}}}
+	err := WithDetails(errors.BadRequest("INVALID_USER", "the user is invalid").WithMetadata(map[string]string{
+		"user":   "1",
+		"status": "ignored",
+	}), violations)
+	tests := []struct {
+		accept      string
+		contentType string
+		members     map[string]interface{}
+	}{
+		{"application/problem+json", ProblemContentType, map[string]interface{}{
+			"type":     "about:blank",
+			"title":    "Bad Request",
+			"status":   float64(400),
+			"detail":   "the user is invalid",
+			"instance": "/users/1",
+			"reason":   "INVALID_USER",
+			"user":     "1",
+			"details": []interface{}{map[string]interface{}{
+				"@type":           "type.googleapis.com/google.rpc.BadRequest",
+				"fieldViolations": []interface{}{map[string]interface{}{"field": "name", "description": "required"}},
+			}},
+		}},
+		{"text/html, application/problem+json;q=0.9", ProblemContentType, nil},
+		{"application/json", "application/json", nil},
+		{"", "application/json", nil},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		if test.accept != "" {
+			req.Header.Set("Accept", test.accept)
+		}
+		w := httptest.NewRecorder()
+		ProblemErrorEncoder(w, req, err)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected %d got %d", test.accept, http.StatusBadRequest, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != test.contentType {
+			t.Errorf("%s: expected %s got %s", test.accept, test.contentType, ct)
+		}
+		if test.members == nil {
+			continue
+		}
+		var members map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &members); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(members, test.members) {
+			t.Errorf("%s: expected %v got %v", test.accept, test.members, members)
+		}
+	}
+}
+
+func TestProblemErrorDecoder(t *testing.T) {
+	srv := NewServer(ErrorEncoder(ProblemErrorEncoder))
+	violations := &errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "name", Description: "required"}}}
+	srv.Route("/").GET("/users/{id}", func(ctx Context) error {
+		return WithDetails(errors.NotFound("USER_NOT_FOUND", "the user is not found").WithMetadata(map[string]string{"user": "1"}), violations)
+	})
+	ts := newTestServer(t, srv)
+
+	tests := []struct {
+		accept      string
+		contentType string
+	}{
+		{"application/problem+json", ProblemContentType},
+		{"application/json", "application/json"},
+	}
+	for _, test := range tests {
+		accept := func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				if tr, ok := transport.FromClientContext(ctx); ok {
+					tr.RequestHeader().Set("Accept", test.accept)
+				}
+				return handler(ctx, req)
+			}
+		}
+		client, err := NewClient(context.Background(),
+			WithEndpoint(strings.TrimPrefix(ts.URL, "http://")),
+			WithMiddleware(accept),
+			WithErrorDecoder(func(ctx context.Context, res *http.Response) error {
+				if ct := res.Header.Get("Content-Type"); ct != test.contentType {
+					t.Errorf("%s: expected %s got %s", test.accept, test.contentType, ct)
+				}
+				return ProblemErrorDecoder(ctx, res)
+			}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = client.Invoke(context.Background(), http.MethodGet, "/users/1", nil, nil)
+		se := errors.FromError(err)
+		if se.Code != http.StatusNotFound || se.Reason != "USER_NOT_FOUND" || se.Message != "the user is not found" {
+			t.Errorf("%s: unexpected error %v", test.accept, se)
+		}
+		if !reflect.DeepEqual(se.Metadata, map[string]string{"user": "1"}) {
+			t.Errorf("%s: expected %v got %v", test.accept, map[string]string{"user": "1"}, se.Metadata)
+		}
+		if got := ErrorDetails(err); !detailsEqual(got, []proto.Message{violations}) {
+			t.Errorf("%s: expected %v got %v", test.accept, violations, got)
+		}
+	}
+}
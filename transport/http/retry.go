@@ -0,0 +1,194 @@
+package http
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+const (
+	defaultInitialBackoff    = 100 * time.Millisecond
+	defaultMaxBackoff        = time.Second
+	defaultBackoffMultiplier = 2
+	defaultRetryBudgetTokens = 10
+)
+
+var defaultRetryableStatusCodes = []int{
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy is the retry policy of client calls.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the original one.
+	MaxAttempts int
+	// InitialBackoff is the backoff before the first retry, default 100ms.
+	InitialBackoff time.Duration
+	// MaxBackoff is the upper bound of the backoff, default 1s.
+	MaxBackoff time.Duration
+	// BackoffMultiplier is the growth factor of the backoff, default 2.
+	BackoffMultiplier float64
+	// Jitter randomizes the backoff by up to the given fraction, in [0, 1].
+	Jitter float64
+	// RetryableStatusCodes is the HTTP status codes to retry, default 502, 503 and 504.
+	RetryableStatusCodes []int
+	// RetryableReasons is the kratos error reasons to retry, in addition to the status codes.
+	RetryableReasons []string
+	// BudgetRatio limits the retries to the given ratio of the calls, zero means no limit.
+	BudgetRatio float64
+	// NonIdempotent allows to retry non idempotent methods such as POST and PATCH.
+	NonIdempotent bool
+}
+
+func (p *RetryPolicy) allowMethod(method string) bool {
+	if p.NonIdempotent {
+		return true
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	se := new(errors.Error)
+	if !errors.As(err, &se) {
+		// transport errors, such as connection refused.
+		return true
+	}
+	codes := p.RetryableStatusCodes
+	if len(codes) == 0 {
+		codes = defaultRetryableStatusCodes
+	}
+	for _, code := range codes {
+		if int(se.Code) == code {
+			return true
+		}
+	}
+	for _, reason := range p.RetryableReasons {
+		if se.Reason == reason {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) backoff(retries int) time.Duration {
+	initial, max, multiplier := p.InitialBackoff, p.MaxBackoff, p.BackoffMultiplier
+	if initial <= 0 {
+		initial = defaultInitialBackoff
+	}
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+	if multiplier < 1 {
+		multiplier = defaultBackoffMultiplier
+	}
+	d := float64(initial) * math.Pow(multiplier, float64(retries-1))
+	if d > float64(max) {
+		d = float64(max)
+	}
+	if p.Jitter > 0 {
+		d *= 1 + p.Jitter*(rand.Float64()*2-1) //nolint:gosec
+	}
+	return time.Duration(d)
+}
+
+// retryBudget is a token bucket shared by the calls of a client,
+// each call deposits the budget ratio, and each retry withdraws a token.
+type retryBudget struct {
+	mu     sync.Mutex
+	tokens float64
+}
+
+func newRetryBudget() *retryBudget {
+	return &retryBudget{tokens: defaultRetryBudgetTokens}
+}
+
+func (b *retryBudget) deposit(ratio float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.tokens+ratio, defaultRetryBudgetTokens)
+}
+
+func (b *retryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Retry is the retry policy call option, it overrides the client retry policy.
+func Retry(policy RetryPolicy) CallOption {
+	return RetryCallOption{Policy: policy}
+}
+
+// RetryCallOption is set retry policy for client call
+type RetryCallOption struct {
+	EmptyCallOption
+	Policy RetryPolicy
+}
+
+func (o RetryCallOption) before(c *callInfo) error {
+	c.retry = &o.Policy
+	return nil
+}
+
+// WithRetry with client retry policy.
+func WithRetry(policy RetryPolicy) ClientOption {
+	return func(o *clientOptions) {
+		o.retry = &policy
+	}
+}
+
+// doRetry sends the request, and retries it according to the policy.
+// The request body is rewound and a node is reselected on each attempt,
+// and each attempt is hedged when there is a hedging policy.
+func (client *Client) doRetry(ctx context.Context, req *http.Request, c callInfo) (*http.Response, error) {
+	policy := c.retry
+	if policy == nil || policy.MaxAttempts <= 1 || !policy.allowMethod(req.Method) ||
+		(req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return client.doHedging(ctx, req, c.hedging)
+	}
+	if policy.BudgetRatio > 0 {
+		client.budget.deposit(policy.BudgetRatio)
+	}
+	for attempt := 1; ; attempt++ {
+		r := req
+		if attempt > 1 && req.GetBody != nil {
+			r = req.Clone(ctx)
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+		res, err := client.doHedging(ctx, r, c.hedging)
+		if err == nil || attempt >= policy.MaxAttempts || !policy.retryable(err) || ctx.Err() != nil {
+			return res, err
+		}
+		if policy.BudgetRatio > 0 && !client.budget.withdraw() {
+			return res, err
+		}
+		timer := time.NewTimer(policy.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return res, err
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	p := &RetryPolicy{}
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		if !p.allowMethod(method) {
+			t.Errorf("expected %s to be retried", method)
+		}
+	}
+	for _, method := range []string{http.MethodPost, http.MethodPatch} {
+		if p.allowMethod(method) {
+			t.Errorf("expected %s not to be retried", method)
+		}
+	}
+	if !(&RetryPolicy{NonIdempotent: true}).allowMethod(http.MethodPost) {
+		t.Error("expected POST to be retried")
+	}
+
+	tests := []struct {
+		policy RetryPolicy
+		err    error
+		want   bool
+	}{
+		{RetryPolicy{}, io.EOF, true},
+		{RetryPolicy{}, errors.ServiceUnavailable("", ""), true},
+		{RetryPolicy{}, errors.InternalServer("", ""), false},
+		{RetryPolicy{RetryableStatusCodes: []int{500}}, errors.InternalServer("", ""), true},
+		{RetryPolicy{RetryableReasons: []string{"DB_BUSY"}}, errors.InternalServer("DB_BUSY", ""), true},
+		{RetryPolicy{RetryableReasons: []string{"DB_BUSY"}}, errors.ServiceUnavailable("", ""), true},
+		{RetryPolicy{RetryableStatusCodes: []int{500}}, errors.ServiceUnavailable("", ""), false},
+		{RetryPolicy{}, context.Canceled, false},
+		{RetryPolicy{}, &url.Error{Op: "Get", URL: "/users", Err: context.DeadlineExceeded}, false},
+	}
+	for _, test := range tests {
+		if got := test.policy.retryable(test.err); got != test.want {
+			t.Errorf("%+v %v: expected %v got %v", test.policy, test.err, test.want, got)
+		}
+	}
+
+	p = &RetryPolicy{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond, BackoffMultiplier: 3}
+	for retries, want := range []time.Duration{10 * time.Millisecond, 30 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond} {
+		if got := p.backoff(retries + 1); got != want {
+			t.Errorf("expected %v got %v", want, got)
+		}
+	}
+	p.Jitter = 0.5
+	for i := 0; i < 10; i++ {
+		if got := p.backoff(1); got < 5*time.Millisecond || got > 15*time.Millisecond {
+			t.Errorf("expected backoff in [5ms, 15ms] got %v", got)
+		}
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	b := newRetryBudget()
+	for i := 0; i < defaultRetryBudgetTokens; i++ {
+		if !b.withdraw() {
+			t.Fatalf("expected withdraw %d to succeed", i)
+		}
+	}
+	if b.withdraw() {
+		t.Error("expected budget to be exhausted")
+	}
+	b.deposit(0.5)
+	if b.withdraw() {
+		t.Error("expected budget to be exhausted")
+	}
+	b.deposit(0.5)
+	if !b.withdraw() {
+		t.Error("expected withdraw to succeed")
+	}
+}
+
+func TestClientRetry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	tests := []struct {
+		name     string
+		method   string
+		failures int32
+		opts     []CallOption
+		calls    int32
+		code     int
+	}{
+		{"recovered", http.MethodGet, 2, nil, 3, 200},
+		{"exhausted", http.MethodGet, 3, nil, 3, 503},
+		{"post not retried", http.MethodPost, 2, nil, 1, 503},
+		{"post retried", http.MethodPost, 2, []CallOption{Retry(RetryPolicy{MaxAttempts: 3, NonIdempotent: true})}, 3, 200},
+		{"call disabled", http.MethodGet, 2, []CallOption{Retry(RetryPolicy{})}, 1, 503},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := newTestUpstream(t, 0, test.failures, http.StatusServiceUnavailable, "")
+			client, err := NewClient(context.Background(), WithEndpoint(strings.TrimPrefix(srv.URL, "http://")), WithRetry(policy))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var reply User
+			err = client.Invoke(context.Background(), test.method, "/users", &User{Name: "kratos"}, &reply, test.opts...)
+			if code := errors.Code(err); code != test.code {
+				t.Errorf("expected %d got %d", test.code, code)
+			}
+			if n := srv.Calls(); n != test.calls {
+				t.Errorf("expected %d calls got %d", test.calls, n)
+			}
+			for _, body := range srv.Bodies() {
+				if body != `{"name":"kratos"}` {
+					t.Errorf("expected rewound body got %s", body)
+				}
+			}
+		})
+	}
+}
+
+func TestClientRetryReason(t *testing.T) {
+	srv := newTestUpstream(t, 0, 1, http.StatusInternalServerError, "DB_BUSY")
+	client, err := NewClient(context.Background(), WithEndpoint(strings.TrimPrefix(srv.URL, "http://")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.Invoke(context.Background(), http.MethodGet, "/users", nil, &User{},
+		Retry(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, RetryableReasons: []string{"DB_BUSY"}}))
+	if err != nil {
+		t.Errorf("expected nil got %v", err)
+	}
+	if n := srv.Calls(); n != 2 {
+		t.Errorf("expected %d calls got %d", 2, n)
+	}
+}
+
+func TestClientRetryBudget(t *testing.T) {
+	srv := newTestUpstream(t, 0, -1, http.StatusServiceUnavailable, "")
+	client, err := NewClient(context.Background(), WithEndpoint(strings.TrimPrefix(srv.URL, "http://")),
+		WithRetry(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, BudgetRatio: 0.5}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		_ = client.Invoke(context.Background(), http.MethodGet, "/users", nil, &User{})
+	}
+	// each call costs a token and deposits half of one, so 10 initial tokens allow 19 retries.
+	if n := srv.Calls(); n != 39 {
+		t.Errorf("expected %d calls got %d", 39, n)
+	}
+}
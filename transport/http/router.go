@@ -6,6 +6,7 @@ import (
 	"path"
 	"sync"
 
+	"github.com/JellyTony/zeus/internal/pathtemplate"
 	"github.com/gin-gonic/gin"
 	"github.com/go-kratos/kratos/v2/middleware"
 )
@@ -17,6 +18,18 @@ type WalkRouteFunc func(RouteInfo) error
 type RouteInfo struct {
 	Path   string
 	Method string
+	// Operation is the operation of the route, e.g. /helloworld.Greeter/SayHello.
+	Operation string
+	// Request and Reply are values of the request and reply types, usually typed nil
+	// pointers such as (*HelloRequest)(nil). Their schemas are derived from the protobuf
+	// descriptors of the proto messages, or by reflection of the Go structs.
+	Request interface{}
+	Reply   interface{}
+	// Body is the request field bound to the body, or "*" for the whole request.
+	// The request fields are bound to the query when it is empty.
+	Body string
+	// ResponseBody is the reply field sent as the response body, the whole reply when it is empty.
+	ResponseBody string
 }
 
 // HandlerFunc defines a function to serve HTTP requests.
@@ -24,10 +37,12 @@ type HandlerFunc func(Context) error
 
 // Router is an HTTP router.
 type Router struct {
-	prefix  string
-	pool    sync.Pool
-	srv     *Server
-	filters []middleware.Middleware
+	prefix   string
+	pool     sync.Pool
+	srv      *Server
+	filters  []middleware.Middleware
+	consumes []string
+	produces []string
 }
 
 func newRouter(prefix string, srv *Server, filters ...middleware.Middleware) *Router {
@@ -47,11 +62,35 @@ func (r *Router) Group(prefix string, filters ...middleware.Middleware) *Router
 	var newFilters []middleware.Middleware
 	newFilters = append(newFilters, r.filters...)
 	newFilters = append(newFilters, filters...)
-	return newRouter(path.Join(r.prefix, prefix), r.srv, newFilters...)
+	g := newRouter(path.Join(r.prefix, prefix), r.srv, newFilters...)
+	g.consumes, g.produces = r.consumes, r.produces
+	return g
+}
+
+// Consumes restricts the media types of the request bodies of the routes of the router, the
+// requests with a body of another media type are rejected with a 415 error. The media types may
+// be wildcards such as "application/*" or "application/*+json".
+func (r *Router) Consumes(mediaTypes ...string) {
+	r.consumes = mediaTypes
+}
+
+// Produces restricts the media types of the responses of the routes of the router, they are
+// negotiated with the Accept header in their order of preference. The requests accepting none
+// of them are rejected with a 406 error.
+func (r *Router) Produces(mediaTypes ...string) {
+	r.produces = mediaTypes
 }
 
 // Handle registers a new route with a matcher for the URL path and method.
+// The path may be a gin pattern such as "/users/:name", or a path template
+// such as "/users/{name}", "/v1/{name=messages/*}" or "/files/{path=**}",
+// with a custom verb such as "/v1/{name=operations/*}:cancel".
 func (r *Router) Handle(method, relativePath string, h HandlerFunc, filters ...middleware.Middleware) {
+	r.handle(method, relativePath, h, false, filters...)
+}
+
+// handle registers a route, the streaming routes outlive the server timeout.
+func (r *Router) handle(method, relativePath string, h HandlerFunc, stream bool, filters ...middleware.Middleware) {
 	next := func(c *gin.Context) {
 		ctx := r.pool.Get().(*wrapper)
 		ctx.Context = c
@@ -62,20 +101,35 @@ func (r *Router) Handle(method, relativePath string, h HandlerFunc, filters ...m
 		ms = append(ms, filters...)
 		chain := middleware.Chain(ms...)
 		nt := func(cc context.Context, req interface{}) (interface{}, error) {
-			err := h(ctx)
-			if err != nil {
-				r.srv.ene(c.Writer, c.Request, err)
+			if err := r.negotiate(c); err != nil {
+				return nil, err
 			}
-			return c.Writer, err
+			return c.Writer, h(ctx)
 		}
 		nt = chain(nt)
-		_, _ = nt(c.Request.Context(), c.Request)
+		// the errors of an event stream are not encoded, the response has been sent.
+		if _, err := nt(c.Request.Context(), c.Request); err != nil && ctx.stream == nil {
+			r.srv.ene(c.Writer, c.Request, err)
+		}
 		ctx.Reset(nil, nil)
 		ctx.Context = nil
 		r.pool.Put(ctx)
 	}
 
-	r.srv.engine.Handle(method, path.Join(r.prefix, relativePath), next)
+	tpl := pathtemplate.MustCompile(path.Join(r.prefix, relativePath))
+	r.srv.templates[tpl.Path()] = tpl
+	if stream {
+		r.srv.streams[method+" "+tpl.Path()] = struct{}{}
+	}
+	r.srv.addRoute(method, tpl, next)
+}
+
+// Describe describes the route registered for a path and method in the router,
+// the description is reported by WalkRoute and documents the route in the OpenAPI document.
+func (r *Router) Describe(method, relativePath string, info RouteInfo) {
+	tpl := pathtemplate.MustCompile(path.Join(r.prefix, relativePath))
+	info.Method, info.Path = method, tpl.Path()
+	r.srv.routes[method+" "+tpl.Path()] = info
 }
 
 // GET registers a new GET route for a path with matching handler in the router.
@@ -122,3 +176,39 @@ func (r *Router) OPTIONS(path string, h HandlerFunc, m ...middleware.Middleware)
 func (r *Router) TRACE(path string, h HandlerFunc, m ...middleware.Middleware) {
 	r.Handle(http.MethodTrace, path, h, m...)
 }
+
+// routeHandlers are the handlers of the routes of a method sharing a gin pattern:
+// the route without a custom verb, and the routes with one.
+type routeHandlers struct {
+	path    string
+	handler gin.HandlerFunc
+	verbs   []verbHandler
+}
+
+type verbHandler struct {
+	tpl     *pathtemplate.Template
+	handler gin.HandlerFunc
+}
+
+// match returns the path and the handler of the route of the request path,
+// the handler is nil when no route matches.
+func (h *routeHandlers) match(path string) (string, gin.HandlerFunc) {
+	for _, v := range h.verbs {
+		if v.tpl.MatchVerb(path) {
+			return v.tpl.Path(), v.handler
+		}
+	}
+	return h.path, h.handler
+}
+
+// paths returns the paths of the routes, the route without a verb first.
+func (h *routeHandlers) paths() []string {
+	paths := make([]string, 0, len(h.verbs)+1)
+	if h.handler != nil {
+		paths = append(paths, h.path)
+	}
+	for _, v := range h.verbs {
+		paths = append(paths, v.tpl.Path())
+	}
+	return paths
+}
@@ -6,12 +6,15 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/JellyTony/zeus/internal/host"
+	"github.com/JellyTony/zeus/internal/testdata/helloworld"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
 )
@@ -205,3 +208,121 @@ func TestHandle(t *testing.T) {
 	r.OPTIONS("/options", h)
 	r.TRACE("/trace", h)
 }
+
+func TestRoutePathTemplate(t *testing.T) {
+	srv := NewServer()
+	route := srv.Route("/")
+	setTemplate := func(ctx Context) {
+		if tr, ok := transport.FromServerContext(ctx); ok {
+			ctx.Response().Header().Set("X-Path-Template", tr.(Transporter).PathTemplate())
+		}
+	}
+	route.GET("/helloworld/{name}", func(ctx Context) error {
+		in := new(helloworld.HelloRequest)
+		if err := ctx.BindVars(in); err != nil {
+			return err
+		}
+		setTemplate(ctx)
+		return ctx.Result(200, &helloworld.HelloReply{Message: in.Name})
+	})
+	route.GET("/v1/{name=messages/*}", func(ctx Context) error {
+		setTemplate(ctx)
+		return ctx.Result(200, &helloworld.HelloReply{Message: ctx.Vars().Get("name")})
+	})
+	route.GET("/files/{path=**}", func(ctx Context) error {
+		setTemplate(ctx)
+		return ctx.Result(200, &helloworld.HelloReply{Message: ctx.Param("path")})
+	})
+
+	tests := []struct {
+		path     string
+		template string
+		want     string
+	}{
+		{"/helloworld/kratos", "/helloworld/{name}", "kratos"},
+		{"/v1/messages/123", "/v1/{name=messages/*}", "messages/123"},
+		{"/files/a/b/c.txt", "/files/{path=**}", "a/b/c.txt"},
+	}
+	for _, test := range tests {
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if res.Code != 200 {
+			t.Fatalf("%s: code: %d", test.path, res.Code)
+		}
+		reply := new(helloworld.HelloReply)
+		if err := json.Unmarshal(res.Body.Bytes(), reply); err != nil {
+			t.Fatal(err)
+		}
+		if reply.Message != test.want {
+			t.Errorf("expected %s got %s", test.want, reply.Message)
+		}
+		if v := res.Header().Get("X-Path-Template"); v != test.template {
+			t.Errorf("expected %s got %s", test.template, v)
+		}
+	}
+}
+
+func TestRouteVerb(t *testing.T) {
+	srv := NewServer()
+	route := srv.Route("/")
+	reply := func(prefix string) HandlerFunc {
+		return func(ctx Context) error {
+			tr, _ := transport.FromServerContext(ctx)
+			ctx.Response().Header().Set("X-Path-Template", tr.(Transporter).PathTemplate())
+			return ctx.String(200, prefix+ctx.Vars().Get("name"))
+		}
+	}
+	route.GET("/v1/{name=operations/*}", reply(""))
+	route.GET("/v1/{name=operations/*}:wait", reply("wait "))
+	route.POST("/v1/{name=operations/*}:cancel", reply("cancel "))
+	route.POST("/v1/operations:batchGet", reply("batchGet"))
+
+	tests := []struct {
+		method   string
+		path     string
+		code     int
+		template string
+		want     string
+	}{
+		{http.MethodGet, "/v1/operations/1", 200, "/v1/{name=operations/*}", "operations/1"},
+		{http.MethodGet, "/v1/operations/1:wait", 200, "/v1/{name=operations/*}:wait", "wait operations/1"},
+		{http.MethodGet, "/v1/operations/1:cancel", 200, "/v1/{name=operations/*}", "operations/1:cancel"},
+		{http.MethodPost, "/v1/operations/1:cancel", 200, "/v1/{name=operations/*}:cancel", "cancel operations/1"},
+		{http.MethodPost, "/v1/operations/1", 404, "", ""},
+		{http.MethodPost, "/v1/operations:batchGet", 200, "/v1/operations:batchGet", "batchGet"},
+		{http.MethodPost, "/v1/operationsx:batchGet", 404, "", ""},
+	}
+	for _, test := range tests {
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, httptest.NewRequest(test.method, test.path, nil))
+		if res.Code != test.code {
+			t.Errorf("%s %s: expected %d got %d", test.method, test.path, test.code, res.Code)
+			continue
+		}
+		if test.code != 200 {
+			continue
+		}
+		if got := res.Body.String(); got != test.want {
+			t.Errorf("%s %s: expected %s got %s", test.method, test.path, test.want, got)
+		}
+		if got := res.Header().Get("X-Path-Template"); got != test.template {
+			t.Errorf("%s %s: expected %s got %s", test.method, test.path, test.template, got)
+		}
+	}
+
+	var routes []string
+	_ = srv.WalkRoute(func(info RouteInfo) error {
+		routes = append(routes, info.Method+" "+info.Path)
+		return nil
+	})
+	sort.Strings(routes)
+	want := []string{
+		"GET /v1/operations/:name$1",
+		"GET /v1/operations/:name$1:wait",
+		"POST /v1/operations/:name$1:cancel",
+		"POST /v1/operations:batchGet",
+	}
+	if !reflect.DeepEqual(routes, want) {
+		t.Errorf("expected %v got %v", want, routes)
+	}
+}
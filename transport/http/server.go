@@ -4,18 +4,26 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/JellyTony/zeus/internal/endpoint"
 	"github.com/JellyTony/zeus/internal/host"
 	"github.com/JellyTony/zeus/internal/matcher"
+	"github.com/JellyTony/zeus/internal/pathtemplate"
 	"github.com/gin-gonic/gin"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/transport"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 var (
@@ -41,13 +49,93 @@ func Address(addr string) ServerOption {
 	}
 }
 
-// Timeout with server timeout.
+// Timeout with server timeout, default 1s. The event stream and websocket routes
+// registered by Router.SSE and Router.WS are not bounded by it.
 func Timeout(timeout time.Duration) ServerOption {
 	return func(s *Server) {
 		s.timeout = timeout
 	}
 }
 
+// ReadTimeout with the maximum duration for reading the entire request, including the body.
+func ReadTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.readTimeout = timeout
+	}
+}
+
+// ReadHeaderTimeout with the amount of time allowed to read request headers.
+func ReadHeaderTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.readHeaderTimeout = timeout
+	}
+}
+
+// WriteTimeout with the maximum duration before timing out writes of the response.
+func WriteTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.writeTimeout = timeout
+	}
+}
+
+// IdleTimeout with the maximum amount of time to wait for the next request when keep-alives are enabled.
+func IdleTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.idleTimeout = timeout
+	}
+}
+
+// MaxHeaderBytes with the maximum number of bytes the server will read parsing the request header.
+func MaxHeaderBytes(n int) ServerOption {
+	return func(s *Server) {
+		s.maxHeaderBytes = n
+	}
+}
+
+// MaxBodySize with the maximum number of bytes of the request body,
+// larger requests are rejected with a 413 error.
+func MaxBodySize(n int64) ServerOption {
+	return func(s *Server) {
+		s.maxBodySize = n
+	}
+}
+
+// PreStopDelay with the delay between marking the server unready and shutting it down,
+// it gives load balancers time to stop routing new requests to the server.
+func PreStopDelay(delay time.Duration) ServerOption {
+	return func(s *Server) {
+		s.preStopDelay = delay
+	}
+}
+
+// H2C with HTTP/2 cleartext, both prior knowledge and HTTP/1.1 upgrade are supported.
+func H2C() ServerOption {
+	return func(s *Server) {
+		s.h2c = true
+	}
+}
+
+// MaxConcurrentStreams with the maximum number of concurrent HTTP/2 streams per connection.
+func MaxConcurrentStreams(n uint32) ServerOption {
+	return func(s *Server) {
+		s.h2s.MaxConcurrentStreams = n
+	}
+}
+
+// MaxReadFrameSize with the largest HTTP/2 frame the server is willing to read.
+func MaxReadFrameSize(n uint32) ServerOption {
+	return func(s *Server) {
+		s.h2s.MaxReadFrameSize = n
+	}
+}
+
+// HTTP2IdleTimeout with the duration until idle HTTP/2 connections are closed.
+func HTTP2IdleTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.h2s.IdleTimeout = timeout
+	}
+}
+
 // Middleware with service middleware option.
 func Middleware(m ...middleware.Middleware) ServerOption {
 	return func(o *Server) {
@@ -55,6 +143,14 @@ func Middleware(m ...middleware.Middleware) ServerOption {
 	}
 }
 
+// NamedMiddleware with a middleware registered under a name, the routes
+// generated by protoc-gen-go-zeus-http reference it by the hints of their methods.
+func NamedMiddleware(name string, m ...middleware.Middleware) ServerOption {
+	return func(o *Server) {
+		o.named[name] = m
+	}
+}
+
 // Filter with HTTP middleware option.
 func Filter(filters ...FilterFunc) ServerOption {
 	return func(o *Server) {
@@ -104,6 +200,14 @@ func TLSConfig(c *tls.Config) ServerOption {
 	}
 }
 
+// TLSCertReloader with a reloading server certificate,
+// it is served through the GetCertificate of the TLS config.
+func TLSCertReloader(r *CertReloader) ServerOption {
+	return func(o *Server) {
+		o.certReloader = r
+	}
+}
+
 // StrictSlash is with mux's StrictSlash
 // If true, when the path pattern is "/path/", accessing "/path" will
 // redirect to the former and vice versa.
@@ -120,25 +224,82 @@ func Listener(lis net.Listener) ServerOption {
 	}
 }
 
+// AdditionalListener with an additional listener served alongside the main one.
+func AdditionalListener(lis net.Listener) ServerOption {
+	return func(s *Server) {
+		s.listeners = append(s.listeners, &serverListener{
+			network: lis.Addr().Network(),
+			address: lis.Addr().String(),
+			lis:     lis,
+		})
+	}
+}
+
+// AdditionalAddress with an additional network address served alongside the main one,
+// e.g. AdditionalAddress("unix", "/var/run/app.sock") or AdditionalAddress("tcp", ":8001").
+func AdditionalAddress(network, address string) ServerOption {
+	return func(s *Server) {
+		s.listeners = append(s.listeners, &serverListener{network: network, address: address})
+	}
+}
+
+type serverListener struct {
+	network  string
+	address  string
+	lis      net.Listener
+	endpoint *url.URL
+}
+
 // Server is an HTTP server wrapper.
 type Server struct {
 	*http.Server
-	lis         net.Listener
-	tlsConf     *tls.Config
-	endpoint    *url.URL
-	err         error
-	network     string
-	address     string
-	timeout     time.Duration
-	filters     []FilterFunc
-	middleware  matcher.Matcher
-	decVars     DecodeRequestFunc
-	decQuery    DecodeRequestFunc
-	decBody     DecodeRequestFunc
-	enc         EncodeResponseFunc
-	ene         EncodeErrorFunc
-	strictSlash bool
-	engine      *gin.Engine
+	lis               net.Listener
+	listeners         []*serverListener
+	tlsConf           *tls.Config
+	certReloader      *CertReloader
+	endpoint          *url.URL
+	err               error
+	network           string
+	address           string
+	timeout           time.Duration
+	readTimeout       time.Duration
+	readHeaderTimeout time.Duration
+	writeTimeout      time.Duration
+	idleTimeout       time.Duration
+	maxHeaderBytes    int
+	maxBodySize       int64
+	preStopDelay      time.Duration
+	draining          int32
+	inflight          int64
+	aborted           int64
+	health            *health
+	probeOpts         *probeOptions
+	probes            map[string]http.HandlerFunc
+	h2c               bool
+	h2s               *http2.Server
+	adminAddress      string
+	adminLis          net.Listener
+	admin             *http.Server
+	ws                wsOptions
+	wsMu              sync.Mutex
+	wsConns           map[*Conn]struct{}
+	sseMu             sync.Mutex
+	sseStreams        map[*EventStream]struct{}
+	filters           []FilterFunc
+	middleware        matcher.Matcher
+	named             map[string][]middleware.Middleware
+	decVars           DecodeRequestFunc
+	decQuery          DecodeRequestFunc
+	decBody           DecodeRequestFunc
+	enc               EncodeResponseFunc
+	ene               EncodeErrorFunc
+	strictSlash       bool
+	engine            *gin.Engine
+	templates         map[string]*pathtemplate.Template
+	routes            map[string]RouteInfo
+	streams           map[string]struct{}
+	handlers          map[string]*routeHandlers
+	openapi           *openAPIOptions
 }
 
 // NewServer creates an HTTP server by options.
@@ -148,25 +309,67 @@ func NewServer(opts ...ServerOption) *Server {
 		address:     ":0",
 		timeout:     1 * time.Second,
 		middleware:  matcher.New(),
+		named:       make(map[string][]middleware.Middleware),
 		decVars:     DefaultRequestVars,
 		decQuery:    DefaultRequestQuery,
 		decBody:     DefaultRequestDecoder,
 		enc:         DefaultResponseEncoder,
 		ene:         DefaultErrorEncoder,
 		strictSlash: true,
+		templates:   make(map[string]*pathtemplate.Template),
+		routes:      make(map[string]RouteInfo),
+		streams:     make(map[string]struct{}),
+		handlers:    make(map[string]*routeHandlers),
+		health:      &health{},
+		probeOpts:   defaultProbeOptions(),
+		probes:      make(map[string]http.HandlerFunc),
+		h2s:         &http2.Server{},
+		ws:          defaultWSOptions(),
+		wsConns:     make(map[*Conn]struct{}),
+		sseStreams:  make(map[*EventStream]struct{}),
 	}
 	for _, o := range opts {
 		o(srv)
 	}
+	if srv.certReloader != nil {
+		if srv.tlsConf == nil {
+			srv.tlsConf = &tls.Config{MinVersion: tls.VersionTLS12}
+		} else {
+			srv.tlsConf = srv.tlsConf.Clone()
+		}
+		srv.tlsConf.GetCertificate = srv.certReloader.GetCertificate
+	}
 	gin.SetMode(gin.ReleaseMode)
 	srv.engine = gin.New()
 	srv.engine.RedirectTrailingSlash = srv.strictSlash
 	srv.engine.Use(srv.filter())
+	srv.registerHealth()
+	srv.registerOpenAPI()
 
+	handler := FilterChain(srv.filters...)(srv.serveProbes(srv.engine))
+	if len(srv.filters) > 0 {
+		handler = withErrorEncoder(srv.ene, handler)
+	}
+	if srv.h2c {
+		handler = h2c.NewHandler(handler, srv.h2s)
+	}
 	srv.Server = &http.Server{
-		Handler:   FilterChain(srv.filters...)(srv.engine),
-		TLSConfig: srv.tlsConf,
+		Handler:           handler,
+		TLSConfig:         srv.tlsConf,
+		ReadTimeout:       srv.readTimeout,
+		ReadHeaderTimeout: srv.readHeaderTimeout,
+		WriteTimeout:      srv.writeTimeout,
+		IdleTimeout:       srv.idleTimeout,
+		MaxHeaderBytes:    srv.maxHeaderBytes,
 	}
+	srv.Server.RegisterOnShutdown(srv.closeWebSockets)
+	srv.Server.RegisterOnShutdown(srv.closeEventStreams)
+	// the HTTP/2 settings and the graceful shutdown of the HTTP/2 connections
+	// are configured for h2c as well.
+	if srv.tlsConf != nil || srv.h2c {
+		srv.err = http2.ConfigureServer(srv.Server, srv.h2s)
+	}
+	srv.newAdmin()
 	return srv
 }
 
@@ -179,17 +382,84 @@ func (s *Server) Use(selector string, m ...middleware.Middleware) {
 	s.middleware.Add(selector, m...)
 }
 
+// MiddlewareByName returns the middleware registered under the names by the NamedMiddleware
+// option, in the order of the names. It panics if a name has not been registered, as the
+// routes would be served without it.
+func (s *Server) MiddlewareByName(names ...string) []middleware.Middleware {
+	ms := make([]middleware.Middleware, 0, len(names))
+	for _, name := range names {
+		m, ok := s.named[name]
+		if !ok {
+			panic(fmt.Sprintf("http: middleware %q is not registered", name))
+		}
+		ms = append(ms, m...)
+	}
+	return ms
+}
+
 // WalkRoute walks the router and all its sub-routers, calling walkFn for each route in the tree.
 func (s *Server) WalkRoute(fn WalkRouteFunc) error {
+	return s.walkPaths(func(method, path string) error {
+		info, ok := s.routes[method+" "+path]
+		if !ok {
+			info = RouteInfo{Method: method, Path: path}
+		}
+		return fn(info)
+	})
+}
+
+// walkPaths calls fn for the method and the path of each route,
+// the routes with a custom verb share a gin route.
+func (s *Server) walkPaths(fn func(method, path string) error) error {
 	for _, route := range s.engine.Routes() {
-		if err := fn(RouteInfo{Method: route.Method, Path: route.Path}); err != nil {
-			return err
+		paths := []string{route.Path}
+		if h, ok := s.handlers[route.Method+" "+route.Path]; ok {
+			paths = h.paths()
+		}
+		for _, path := range paths {
+			if err := fn(route.Method, path); err != nil {
+				return err
+			}
 		}
 	}
-
 	return nil
 }
 
+// addRoute registers the handler of a route of the routers. The routes of a method whose
+// templates only differ by their custom verb share a gin route, which dispatches the
+// requests by the verb of their path.
+func (s *Server) addRoute(method string, tpl *pathtemplate.Template, handler gin.HandlerFunc) {
+	key := method + " " + tpl.Pattern()
+	h, ok := s.handlers[key]
+	if !ok {
+		h = &routeHandlers{path: tpl.Pattern()}
+		s.handlers[key] = h
+		s.engine.Handle(method, tpl.Pattern(), func(c *gin.Context) {
+			if _, next := h.match(c.Request.URL.Path); next != nil {
+				next(c)
+				return
+			}
+			http.NotFound(c.Writer, c.Request)
+		})
+	}
+	if method == http.MethodGet {
+		delete(s.probes, tpl.Pattern())
+	}
+	if tpl.Verb() == "" {
+		if h.handler != nil {
+			panic(fmt.Sprintf("http: route %s %s is already registered", method, tpl.Template()))
+		}
+		h.handler = handler
+		return
+	}
+	for _, v := range h.verbs {
+		if v.tpl.Verb() == tpl.Verb() {
+			panic(fmt.Sprintf("http: route %s %s is already registered", method, tpl.Template()))
+		}
+	}
+	h.verbs = append(h.verbs, verbHandler{tpl: tpl, handler: handler})
+}
+
 // Route registers an HTTP router.
 func (s *Server) Route(prefix string, ms ...middleware.Middleware) *Router {
 	return newRouter(prefix, s, ms...)
@@ -197,16 +467,19 @@ func (s *Server) Route(prefix string, ms ...middleware.Middleware) *Router {
 
 // Handle registers a new route with a matcher for the URL path.
 func (s *Server) Handle(path string, h http.Handler) {
+	delete(s.probes, path)
 	s.engine.Any(path, gin.WrapH(h))
 }
 
 // HandlePrefix registers a new route with a matcher for the URL path prefix.
 func (s *Server) HandlePrefix(prefix string, h http.Handler) {
+	delete(s.probes, prefix)
 	s.engine.Any(prefix, gin.WrapH(h))
 }
 
 // HandleFunc registers a new route with a matcher for the URL path.
 func (s *Server) HandleFunc(path string, h http.HandlerFunc) {
+	delete(s.probes, path)
 	s.engine.Any(path, gin.WrapF(h))
 }
 
@@ -219,13 +492,47 @@ func (s *Server) Engine() *gin.Engine {
 	return s.engine
 }
 
+// InFlight returns the number of requests currently being served.
+func (s *Server) InFlight() int64 {
+	return atomic.LoadInt64(&s.inflight)
+}
+
+// Aborted returns the number of in-flight requests aborted by the last Stop.
+func (s *Server) Aborted() int64 {
+	return atomic.LoadInt64(&s.aborted)
+}
+
 func (s *Server) filter() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		atomic.AddInt64(&s.inflight, 1)
+		defer atomic.AddInt64(&s.inflight, -1)
+
+		if s.maxBodySize > 0 {
+			if err := limitBody(c.Request, s.maxBodySize); err != nil {
+				s.ene(c.Writer, c.Request, err)
+				c.Abort()
+				return
+			}
+		}
+
+		route := c.FullPath()
+		if h, ok := s.handlers[c.Request.Method+" "+route]; ok {
+			route, _ = h.match(c.Request.URL.Path)
+		}
+		pathTemplate := route
+		if tpl, ok := s.templates[route]; ok {
+			pathTemplate = tpl.Template()
+			if tpl.Templated() {
+				c.Params = tpl.Params(c.Params)
+			}
+		}
 		var (
 			ctx    context.Context
 			cancel context.CancelFunc
 		)
-		if s.timeout > 0 {
+		// the websocket connections and the event streams outlive the server timeout.
+		_, stream := s.streams[c.Request.Method+" "+route]
+		if s.timeout > 0 && !stream {
 			ctx, cancel = context.WithTimeout(c.Request.Context(), s.timeout)
 		} else {
 			ctx, cancel = context.WithCancel(c.Request.Context())
@@ -233,16 +540,17 @@ func (s *Server) filter() gin.HandlerFunc {
 		defer cancel()
 
 		tr := &Transport{
-			operation:    c.FullPath(),
-			pathTemplate: c.FullPath(),
+			operation:    pathTemplate,
+			pathTemplate: pathTemplate,
 			reqHeader:    headerCarrier(c.Request.Header),
 			replyHeader:  headerCarrier(c.Writer.Header()),
 			request:      c.Request,
+			peer:         newPeer(c.Request.TLS),
 		}
 		if s.endpoint != nil {
 			tr.endpoint = s.endpoint.String()
 		}
-		tr.request = c.Request.WithContext(transport.NewServerContext(ctx, tr))
+		tr.request = c.Request.WithContext(transport.NewServerContext(NewGinContext(ctx, c), tr))
 		c.Request = tr.request
 
 		h := func(ctx context.Context, req interface{}) (interface{}, error) {
@@ -254,9 +562,13 @@ func (s *Server) filter() gin.HandlerFunc {
 			h = middleware.Chain(next...)(h)
 		}
 
-		_, err := h(ctx, c.Request)
+		_, err := h(c.Request.Context(), c.Request)
 		if err != nil {
 			_ = c.Error(err)
+			// the request has been rejected by the middleware.
+			if !c.Writer.Written() {
+				s.ene(c.Writer, c.Request, err)
+			}
 		}
 	}
 }
@@ -273,6 +585,76 @@ func (s *Server) Endpoint() (*url.URL, error) {
 	return s.endpoint, nil
 }
 
+// Endpoints return the real addresses of all listeners to registry endpoints,
+// the first one is the main listener.
+// examples:
+//
+//	https://127.0.0.1:8000
+//	unix:///var/run/app.sock
+func (s *Server) Endpoints() ([]*url.URL, error) {
+	if err := s.listenAndEndpoint(); err != nil {
+		return nil, err
+	}
+	endpoints := make([]*url.URL, 0, len(s.listeners)+1)
+	endpoints = append(endpoints, s.endpoint)
+	for _, l := range s.listeners {
+		endpoints = append(endpoints, l.endpoint)
+	}
+	return endpoints, nil
+}
+
+// Registrar returns a registrar that advertises the endpoints of all the listeners of the
+// servers, the applications only advertise the Endpoint of each server:
+//
+//	kratos.New(kratos.Server(srv), kratos.Registrar(http.Registrar(r, srv)))
+func Registrar(r registry.Registrar, servers ...*Server) registry.Registrar {
+	return &serverRegistrar{r: r, servers: servers}
+}
+
+type serverRegistrar struct {
+	r       registry.Registrar
+	servers []*Server
+}
+
+func (r *serverRegistrar) Register(ctx context.Context, service *registry.ServiceInstance) error {
+	service, err := r.instance(service)
+	if err != nil {
+		return err
+	}
+	return r.r.Register(ctx, service)
+}
+
+func (r *serverRegistrar) Deregister(ctx context.Context, service *registry.ServiceInstance) error {
+	service, err := r.instance(service)
+	if err != nil {
+		return err
+	}
+	return r.r.Deregister(ctx, service)
+}
+
+// instance returns a copy of the service instance with the endpoints of the servers it lacks.
+func (r *serverRegistrar) instance(service *registry.ServiceInstance) (*registry.ServiceInstance, error) {
+	ins := *service
+	ins.Endpoints = append([]string(nil), service.Endpoints...)
+	seen := make(map[string]bool, len(ins.Endpoints))
+	for _, e := range ins.Endpoints {
+		seen[e] = true
+	}
+	for _, srv := range r.servers {
+		endpoints, err := srv.Endpoints()
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range endpoints {
+			if !seen[e.String()] {
+				seen[e.String()] = true
+				ins.Endpoints = append(ins.Endpoints, e.String())
+			}
+		}
+	}
+	return &ins, nil
+}
+
 // Start start the HTTP server.
 func (s *Server) Start(ctx context.Context) error {
 	if err := s.listenAndEndpoint(); err != nil {
@@ -281,12 +663,38 @@ func (s *Server) Start(ctx context.Context) error {
 	s.BaseContext = func(net.Listener) context.Context {
 		return ctx
 	}
-	log.Infof("[HTTP] server listening on: %s", s.lis.Addr().String())
+	if err := s.startAdmin(ctx); err != nil {
+		return err
+	}
+	listeners := make([]net.Listener, 0, len(s.listeners)+1)
+	listeners = append(listeners, s.lis)
+	for _, l := range s.listeners {
+		listeners = append(listeners, l.lis)
+	}
+	errc := make(chan error, len(listeners))
+	for _, lis := range listeners {
+		log.Infof("[HTTP] server listening on: %s", lis.Addr().String())
+		go func(lis net.Listener) {
+			errc <- s.serve(lis)
+		}(lis)
+	}
+	for range listeners {
+		if err := <-errc; err != nil {
+			// the other listeners are not left serving.
+			_ = s.Close()
+			_ = s.stopAdmin(ctx)
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) serve(lis net.Listener) error {
 	var err error
 	if s.tlsConf != nil {
-		err = s.ServeTLS(s.lis, "", "")
+		err = s.ServeTLS(lis, "", "")
 	} else {
-		err = s.Serve(s.lis)
+		err = s.Serve(lis)
 	}
 	if !errors.Is(err, http.ErrServerClosed) {
 		return err
@@ -295,14 +703,57 @@ func (s *Server) Start(ctx context.Context) error {
 }
 
 // Stop stop the HTTP server.
+// The server is marked unready first and waits for the pre-stop delay,
+// then in-flight requests are drained until ctx is done, and the remaining
+// ones are aborted.
 func (s *Server) Stop(ctx context.Context) error {
 	log.Info("[HTTP] server stopping")
-	return s.Shutdown(ctx)
+	atomic.StoreInt32(&s.draining, 1)
+	if s.preStopDelay > 0 {
+		log.Infof("[HTTP] server draining, waiting %s before shutdown", s.preStopDelay)
+		timer := time.NewTimer(s.preStopDelay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+	defer func() {
+		if err := s.stopAdmin(ctx); err != nil {
+			log.Errorf("[HTTP] admin server stop error: %v", err)
+		}
+	}()
+	err := s.Shutdown(ctx)
+	if err == nil {
+		err = s.drain(ctx)
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		aborted := atomic.LoadInt64(&s.inflight)
+		atomic.StoreInt64(&s.aborted, aborted)
+		log.Warnf("[HTTP] server shutdown timeout, %d in-flight requests aborted", aborted)
+		_ = s.Close()
+	}
+	return err
+}
+
+// drain waits for the in-flight requests of the hijacked connections, such as the
+// h2c connections, which are not tracked by the shutdown of the http.Server.
+func (s *Server) drain(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for atomic.LoadInt64(&s.inflight) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
 }
 
 func (s *Server) listenAndEndpoint() error {
 	if s.lis == nil {
-		lis, err := net.Listen(s.network, s.address)
+		lis, err := listen(s.network, s.address)
 		if err != nil {
 			s.err = err
 			return err
@@ -310,12 +761,58 @@ func (s *Server) listenAndEndpoint() error {
 		s.lis = lis
 	}
 	if s.endpoint == nil {
-		addr, err := host.Extract(s.address, s.lis)
+		ept, err := s.listenerEndpoint(s.address, s.lis)
 		if err != nil {
 			s.err = err
 			return err
 		}
-		s.endpoint = endpoint.NewEndpoint(endpoint.Scheme("http", s.tlsConf != nil), addr)
+		s.endpoint = ept
+	}
+	for _, l := range s.listeners {
+		if l.lis == nil {
+			lis, err := listen(l.network, l.address)
+			if err != nil {
+				s.err = err
+				return err
+			}
+			l.lis = lis
+		}
+		if l.endpoint == nil {
+			ept, err := s.listenerEndpoint(l.address, l.lis)
+			if err != nil {
+				s.err = err
+				return err
+			}
+			l.endpoint = ept
+		}
 	}
 	return s.err
 }
+
+func (s *Server) listenerEndpoint(address string, lis net.Listener) (*url.URL, error) {
+	if lis.Addr().Network() == "unix" {
+		return &url.URL{Scheme: "unix", Path: lis.Addr().String()}, nil
+	}
+	addr, err := host.Extract(address, lis)
+	if err != nil {
+		return nil, err
+	}
+	return endpoint.NewEndpoint(endpoint.Scheme("http", s.tlsConf != nil), addr), nil
+}
+
+// listen announces on the network address, a stale unix socket file is removed first.
+func listen(network, address string) (net.Listener, error) {
+	if network == "unix" {
+		if fi, err := os.Stat(address); err == nil && fi.Mode()&os.ModeSocket != 0 {
+			// the socket of a live process accepts connections, only a stale socket is removed.
+			if conn, err := net.DialTimeout(network, address, time.Second); err == nil {
+				_ = conn.Close()
+				return nil, fmt.Errorf("listen unix %s: address already in use", address)
+			}
+			if err = os.Remove(address); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return net.Listen(network, address)
+}
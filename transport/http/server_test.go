@@ -9,6 +9,10 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -18,6 +22,8 @@ import (
 	"github.com/gin-gonic/gin"
 	kratoserrors "github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/registry"
+	"golang.org/x/net/http2"
 )
 
 var h = func(w http.ResponseWriter, r *http.Request) {
@@ -43,6 +49,30 @@ func newHandleFuncWrapper(fn http.HandlerFunc) http.Handler {
 	return &handleFuncWrapper{fn: fn}
 }
 
+// newTestServer serves h on a test server closed when the test ends.
+func newTestServer(t *testing.T, h http.Handler) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// startTestServer starts srv and returns its endpoint, the test stops it.
+func startTestServer(t *testing.T, srv *Server) *url.URL {
+	t.Helper()
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		if err := srv.Start(context.Background()); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	return e
+}
+
 func TestServeHTTP(t *testing.T) {
 	ln, err := net.Listen("tcp", ":0")
 	if err != nil {
@@ -364,6 +394,101 @@ func TestTimeout(t *testing.T) {
 	}
 }
 
+func TestHTTPServerOptions(t *testing.T) {
+	srv := NewServer(
+		ReadTimeout(1*time.Second),
+		ReadHeaderTimeout(2*time.Second),
+		WriteTimeout(3*time.Second),
+		IdleTimeout(4*time.Second),
+		MaxHeaderBytes(1024),
+		MaxBodySize(2048),
+	)
+	if srv.ReadTimeout != 1*time.Second {
+		t.Errorf("expected %v got %v", 1*time.Second, srv.ReadTimeout)
+	}
+	if srv.ReadHeaderTimeout != 2*time.Second {
+		t.Errorf("expected %v got %v", 2*time.Second, srv.ReadHeaderTimeout)
+	}
+	if srv.WriteTimeout != 3*time.Second {
+		t.Errorf("expected %v got %v", 3*time.Second, srv.WriteTimeout)
+	}
+	if srv.IdleTimeout != 4*time.Second {
+		t.Errorf("expected %v got %v", 4*time.Second, srv.IdleTimeout)
+	}
+	if srv.MaxHeaderBytes != 1024 {
+		t.Errorf("expected %v got %v", 1024, srv.MaxHeaderBytes)
+	}
+	if srv.maxBodySize != 2048 {
+		t.Errorf("expected %v got %v", 2048, srv.maxBodySize)
+	}
+}
+
+func TestHTTPServerLimits(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(ReadHeaderTimeout(200*time.Millisecond), MaxBodySize(16))
+	srv.Route("/").POST("/users", func(ctx Context) error {
+		var in User
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		return ctx.Result(200, &in)
+	})
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Start(ctx)
+	}()
+	defer func() {
+		_ = srv.Stop(ctx)
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	}()
+
+	body := `{"name":"a name longer than the limit"}`
+	tests := []struct {
+		name string
+		body io.Reader
+		code int
+	}{
+		{"small", strings.NewReader(`{"name":"zeus"}`), http.StatusOK},
+		{"declared", strings.NewReader(body), http.StatusRequestEntityTooLarge},
+		// the length of the chunked body is only known once read.
+		{"chunked", io.MultiReader(strings.NewReader(body)), http.StatusRequestEntityTooLarge},
+	}
+	for _, test := range tests {
+		resp, err := http.Post(e.String()+"/users", "application/json", test.body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != test.code {
+			t.Errorf("%s: expected %d got %d", test.name, test.code, resp.StatusCode)
+		}
+	}
+
+	// the connection is closed when the header is not sent within the ReadHeaderTimeout.
+	conn, err := net.Dial("tcp", e.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err = conn.Write([]byte("GET /users HTTP/1.1\r\nHost: zeus\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	start := time.Now()
+	if _, err = conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("expected %v got %v", io.EOF, err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the connection closed after %v got %v", 200*time.Millisecond, elapsed)
+	}
+}
+
 func TestLogger(t *testing.T) {
 	// todo
 }
@@ -413,6 +538,34 @@ func TestStrictSlash(t *testing.T) {
 	}
 }
 
+func TestNamedMiddleware(t *testing.T) {
+	var calls []string
+	named := func(name string) middleware.Middleware {
+		return func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				calls = append(calls, name)
+				return handler(ctx, req)
+			}
+		}
+	}
+	srv := NewServer(NamedMiddleware("auth", named("auth")), NamedMiddleware("audit", named("audit")))
+	ms := srv.MiddlewareByName("audit", "auth")
+	if len(ms) != 2 {
+		t.Fatalf("expected %d middleware got %d", 2, len(ms))
+	}
+	_, _ = middleware.Chain(ms...)(func(context.Context, interface{}) (interface{}, error) { return nil, nil })(context.Background(), nil)
+	if !reflect.DeepEqual(calls, []string{"audit", "auth"}) {
+		t.Errorf("expected %v got %v", []string{"audit", "auth"}, calls)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for an unregistered middleware")
+		}
+	}()
+	srv.MiddlewareByName("missing")
+}
+
 func TestListener(t *testing.T) {
 	lis, err := net.Listen("tcp", ":0")
 	if err != nil {
@@ -427,3 +580,389 @@ func TestListener(t *testing.T) {
 		t.Errorf("expected not empty")
 	}
 }
+
+// waitUntil waits for the condition to hold, up to 5 seconds.
+func waitUntil(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("the condition does not hold after 5s")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServerDrain(t *testing.T) {
+	tests := []struct {
+		name    string
+		release bool
+		err     error
+		code    int
+		aborted int64
+	}{
+		// the in-flight request completes while the server drains.
+		{"drained", true, nil, http.StatusOK, 0},
+		// the in-flight request outlives the stop deadline.
+		{"aborted", false, context.DeadlineExceeded, 0, 1},
+	}
+	for _, test := range tests {
+		ctx := context.Background()
+		srv := NewServer(PreStopDelay(500 * time.Millisecond))
+		release := make(chan struct{})
+		srv.Route("/").GET("/slow", func(ctx Context) error {
+			<-release
+			return ctx.String(200, "slow")
+		})
+		e, err := srv.Endpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		done := make(chan error, 1)
+		go func() {
+			done <- srv.Start(ctx)
+		}()
+		ready := func() int {
+			resp, err := http.Get(e.String() + "/healthz/ready")
+			if err != nil {
+				t.Errorf("%s: %v", test.name, err)
+				return 0
+			}
+			resp.Body.Close()
+			return resp.StatusCode
+		}
+		if code := ready(); code != http.StatusOK {
+			t.Fatalf("%s: expected %d got %d", test.name, http.StatusOK, code)
+		}
+
+		slow := make(chan int, 1)
+		go func() {
+			resp, err := http.Get(e.String() + "/slow")
+			if err != nil {
+				slow <- 0
+				return
+			}
+			resp.Body.Close()
+			slow <- resp.StatusCode
+		}()
+		waitUntil(t, func() bool { return srv.InFlight() == 1 })
+
+		stopped := make(chan error, 1)
+		go func() {
+			ctx, cancel := context.WithTimeout(ctx, time.Second)
+			defer cancel()
+			stopped <- srv.Stop(ctx)
+		}()
+		// the server is unready before it shuts down.
+		waitUntil(t, func() bool { return ready() == http.StatusServiceUnavailable })
+		if test.release {
+			close(release)
+		}
+		if err := <-stopped; !errors.Is(err, test.err) {
+			t.Errorf("%s: expected %v got %v", test.name, test.err, err)
+		}
+		if code := <-slow; code != test.code {
+			t.Errorf("%s: expected %d got %d", test.name, test.code, code)
+		}
+		if srv.Aborted() != test.aborted {
+			t.Errorf("%s: expected %d got %d", test.name, test.aborted, srv.Aborted())
+		}
+		if !test.release {
+			close(release)
+		}
+		if err := <-done; err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		}
+	}
+}
+
+func TestServerH2C(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(
+		H2C(),
+		MaxConcurrentStreams(10),
+		MaxReadFrameSize(1<<20),
+		HTTP2IdleTimeout(time.Minute),
+	)
+	if srv.h2s.MaxConcurrentStreams != 10 {
+		t.Errorf("expected %v got %v", 10, srv.h2s.MaxConcurrentStreams)
+	}
+	if srv.h2s.MaxReadFrameSize != 1<<20 {
+		t.Errorf("expected %v got %v", 1<<20, srv.h2s.MaxReadFrameSize)
+	}
+	if srv.h2s.IdleTimeout != time.Minute {
+		t.Errorf("expected %v got %v", time.Minute, srv.h2s.IdleTimeout)
+	}
+	srv.Route("/").GET("/proto", func(ctx Context) error {
+		return ctx.String(200, ctx.Request().Proto)
+	})
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Start(ctx)
+	}()
+
+	tests := []struct {
+		name   string
+		client *http.Client
+		proto  string
+	}{
+		{"http/1.1", http.DefaultClient, "HTTP/1.1"},
+		{"h2c", &http.Client{Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		}}, "HTTP/2.0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := test.client.Get(e.String() + "/proto")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != test.proto {
+				t.Errorf("expected %s got %s", test.proto, data)
+			}
+		})
+	}
+	if err := srv.Stop(ctx); err != nil {
+		t.Error(err)
+	}
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestServerH2CDrain(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(H2C())
+	release := make(chan struct{})
+	srv.Route("/").GET("/slow", func(ctx Context) error {
+		<-release
+		return ctx.String(200, ctx.Request().Proto)
+	})
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Start(ctx)
+	}()
+
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	slow := make(chan string, 1)
+	go func() {
+		resp, err := client.Get(e.String() + "/slow")
+		if err != nil {
+			slow <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		slow <- string(data)
+	}()
+	waitUntil(t, func() bool { return srv.InFlight() == 1 })
+
+	stopped := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		stopped <- srv.Stop(ctx)
+	}()
+	// the hijacked h2c connection is drained before Stop returns.
+	select {
+	case err := <-stopped:
+		t.Fatalf("expected Stop to wait for the in-flight request got %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	close(release)
+	if err := <-stopped; err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	if proto := <-slow; proto != "HTTP/2.0" {
+		t.Errorf("expected %s got %s", "HTTP/2.0", proto)
+	}
+	if srv.Aborted() != 0 {
+		t.Errorf("expected %d got %d", 0, srv.Aborted())
+	}
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestServerListeners(t *testing.T) {
+	ctx := context.Background()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sock := filepath.Join(t.TempDir(), "zeus.sock")
+	srv := NewServer(
+		AdditionalAddress("unix", sock),
+		AdditionalListener(lis),
+	)
+	srv.HandleFunc("/index", h)
+	endpoints, err := srv.Endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpoints) != 3 {
+		t.Fatalf("expected %d endpoints got %v", 3, endpoints)
+	}
+	if e := endpoints[1]; e.Scheme != "unix" || e.Path != sock {
+		t.Errorf("expected unix://%s got %v", sock, e)
+	}
+	if e := endpoints[2]; e.Scheme != "http" || e.Host != lis.Addr().String() {
+		t.Errorf("expected http://%s got %v", lis.Addr(), e)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Start(ctx)
+	}()
+
+	unixClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", sock)
+		},
+	}}
+	tests := []struct {
+		client *http.Client
+		url    string
+	}{
+		{http.DefaultClient, endpoints[0].String() + "/index"},
+		{unixClient, "http://unix/index"},
+		{http.DefaultClient, endpoints[2].String() + "/index"},
+	}
+	for _, test := range tests {
+		resp, err := test.client.Get(test.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: expected %d got %d", test.url, http.StatusOK, resp.StatusCode)
+		}
+	}
+
+	if err = srv.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = net.Dial("tcp", lis.Addr().String()); err == nil {
+		t.Error("expected additional listener to be closed")
+	}
+	if _, err = os.Stat(sock); !os.IsNotExist(err) {
+		t.Errorf("expected unix socket to be removed got %v", err)
+	}
+	if err = <-done; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestServerUnixSocket(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "zeus.sock")
+	lis, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the socket of a live process is kept.
+	if _, err = NewServer(Network("unix"), Address(sock)).Endpoint(); err == nil {
+		t.Error("expected the socket in use to fail")
+	}
+	if _, err = net.Dial("unix", sock); err != nil {
+		t.Errorf("expected the socket to be served got %v", err)
+	}
+	// the stale socket of a dead process is replaced.
+	lis.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = lis.Close()
+	srv := NewServer(Network("unix"), Address(sock))
+	if _, err = srv.Endpoint(); err != nil {
+		t.Errorf("expected the stale socket to be replaced got %v", err)
+	}
+	_ = srv.lis.Close()
+}
+
+// failingListener fails to accept connections.
+type failingListener struct {
+	net.Listener
+}
+
+func (l failingListener) Accept() (net.Conn, error) {
+	return nil, errors.New("accept failed")
+}
+
+func TestServerListenerFailure(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := NewServer(AdditionalListener(failingListener{lis}))
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = srv.Start(context.Background()); err == nil || err.Error() != "accept failed" {
+		t.Errorf("expected %v got %v", "accept failed", err)
+	}
+	// the main listener is shut down with the failing one.
+	if _, err = net.Dial("tcp", e.Host); err == nil {
+		t.Error("expected the main listener to be closed")
+	}
+}
+
+type testRegistrar struct {
+	registered   []string
+	deregistered []string
+}
+
+func (r *testRegistrar) Register(_ context.Context, ins *registry.ServiceInstance) error {
+	r.registered = ins.Endpoints
+	return nil
+}
+
+func (r *testRegistrar) Deregister(_ context.Context, ins *registry.ServiceInstance) error {
+	r.deregistered = ins.Endpoints
+	return nil
+}
+
+func TestRegistrar(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "zeus.sock")
+	srv := NewServer(AdditionalAddress("unix", sock))
+	endpoints, err := srv.Endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = srv.Close() }()
+	r := &testRegistrar{}
+	ins := &registry.ServiceInstance{Name: "zeus", Endpoints: []string{endpoints[0].String(), "grpc://127.0.0.1:9000"}}
+	want := []string{endpoints[0].String(), "grpc://127.0.0.1:9000", endpoints[1].String()}
+	if err = Registrar(r, srv).Register(context.Background(), ins); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r.registered, want) {
+		t.Errorf("expected %v got %v", want, r.registered)
+	}
+	if err = Registrar(r, srv).Deregister(context.Background(), ins); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r.deregistered, want) {
+		t.Errorf("expected %v got %v", want, r.deregistered)
+	}
+	if len(ins.Endpoints) != 2 {
+		t.Errorf("expected the instance to be unchanged got %v", ins.Endpoints)
+	}
+}
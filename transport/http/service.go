@@ -0,0 +1,311 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var _ grpc.ServiceRegistrar = (*Server)(nil)
+
+// RegisterService registers a gRPC service implementation and exposes its unary methods
+// over HTTP. The routes and the bindings of the requests and the replies are built at
+// runtime from the google.api.http annotations of the service descriptor, which must be
+// registered in the global protobuf registry by the generated code of the service.
+//
+// The server is a grpc.ServiceRegistrar, so the generated RegisterXXXServer functions
+// can be used with it. The methods without annotations and the streaming methods are not
+// exposed, and an invalid annotation panics as the routes registered by the router do.
+// The paths may have a custom verb, such as /v1/{name=operations/*}:cancel.
+func (s *Server) RegisterService(sd *grpc.ServiceDesc, ss interface{}) {
+	if ss != nil {
+		ht := reflect.TypeOf(sd.HandlerType).Elem()
+		if st := reflect.TypeOf(ss); !st.Implements(ht) {
+			panic(fmt.Sprintf("http: RegisterService found the handler of type %v that does not satisfy %v", st, ht))
+		}
+	}
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(sd.ServiceName))
+	if err != nil {
+		panic(fmt.Sprintf("http: RegisterService can't find the descriptor of %s: %v", sd.ServiceName, err))
+	}
+	service, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		panic(fmt.Sprintf("http: RegisterService found %s that is not a service", sd.ServiceName))
+	}
+	r := s.Route("/")
+	for i := range sd.Methods {
+		desc := &sd.Methods[i]
+		md := service.Methods().ByName(protoreflect.Name(desc.MethodName))
+		if md == nil {
+			panic(fmt.Sprintf("http: RegisterService can't find the method %s of %s", desc.MethodName, sd.ServiceName))
+		}
+		rule, ok := proto.GetExtension(md.Options(), annotations.E_Http).(*annotations.HttpRule)
+		if !ok || rule == nil {
+			continue
+		}
+		op := fmt.Sprintf("/%s/%s", sd.ServiceName, desc.MethodName)
+		for _, b := range append([]*annotations.HttpRule{rule}, rule.AdditionalBindings...) {
+			method, path := httpRulePattern(b)
+			if path == "" {
+				panic(fmt.Sprintf("http: RegisterService found the method %s without a path", op))
+			}
+			r.Handle(method, path, serviceHandler(ss, desc, newBinding(md, b), op))
+			r.Describe(method, path, RouteInfo{
+				Operation:    op,
+				Request:      messageZero(md.Input()),
+				Reply:        messageZero(md.Output()),
+				Body:         b.Body,
+				ResponseBody: b.ResponseBody,
+			})
+		}
+	}
+}
+
+// messageZero returns the zero value of a message type, of the generated type when it is registered.
+func messageZero(md protoreflect.MessageDescriptor) proto.Message {
+	if mt, err := protoregistry.GlobalTypes.FindMessageByName(md.FullName()); err == nil {
+		return mt.Zero().Interface()
+	}
+	return dynamicpb.NewMessageType(md).Zero().Interface()
+}
+
+func httpRulePattern(rule *annotations.HttpRule) (string, string) {
+	switch p := rule.Pattern.(type) {
+	case *annotations.HttpRule_Get:
+		return http.MethodGet, p.Get
+	case *annotations.HttpRule_Put:
+		return http.MethodPut, p.Put
+	case *annotations.HttpRule_Post:
+		return http.MethodPost, p.Post
+	case *annotations.HttpRule_Delete:
+		return http.MethodDelete, p.Delete
+	case *annotations.HttpRule_Patch:
+		return http.MethodPatch, p.Patch
+	case *annotations.HttpRule_Custom:
+		return p.Custom.GetKind(), p.Custom.GetPath()
+	}
+	return "", ""
+}
+
+// ruleBinding is how the request and the reply of a method are mapped to HTTP.
+type ruleBinding struct {
+	// wholeBody is set with body "*", the body is the request and the query is ignored.
+	wholeBody bool
+	// body is the request field bound to the body.
+	body protoreflect.FieldDescriptor
+	// responseBody is the reply field sent as the response body.
+	responseBody protoreflect.FieldDescriptor
+}
+
+func newBinding(md protoreflect.MethodDescriptor, rule *annotations.HttpRule) *ruleBinding {
+	b := &ruleBinding{wholeBody: rule.Body == "*"}
+	if rule.Body != "" && !b.wholeBody {
+		if b.body = md.Input().Fields().ByName(protoreflect.Name(rule.Body)); b.body == nil {
+			panic(fmt.Sprintf("http: body field %s not found in %s", rule.Body, md.Input().FullName()))
+		}
+	}
+	if rule.ResponseBody != "" {
+		if b.responseBody = md.Output().Fields().ByName(protoreflect.Name(rule.ResponseBody)); b.responseBody == nil {
+			panic(fmt.Sprintf("http: response body field %s not found in %s", rule.ResponseBody, md.Output().FullName()))
+		}
+	}
+	return b
+}
+
+func serviceHandler(ss interface{}, desc *grpc.MethodDesc, b *ruleBinding, op string) HandlerFunc {
+	return func(ctx Context) error {
+		SetOperation(ctx, op)
+		dec := func(v interface{}) error {
+			return b.bind(ctx, v.(proto.Message))
+		}
+		// the middleware runs as the interceptor, once the request has been decoded.
+		interceptor := func(c context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			return ctx.Middleware(middleware.Handler(handler))(c, req)
+		}
+		out, err := desc.Handler(ss, ctx, dec, interceptor)
+		if err != nil {
+			return err
+		}
+		reply, err := b.reply(ctx.Request(), out.(proto.Message))
+		if err != nil {
+			return err
+		}
+		return ctx.Result(200, reply)
+	}
+}
+
+// bind decodes the query, the body and the path variables into the request,
+// in this order so the body overrides the query and the path overrides both.
+func (b *ruleBinding) bind(ctx Context, in proto.Message) error {
+	if !b.wholeBody {
+		if err := ctx.BindQuery(in); err != nil {
+			return err
+		}
+	}
+	switch {
+	case b.wholeBody:
+		if err := ctx.Bind(in); err != nil {
+			return err
+		}
+	case b.body != nil:
+		if err := bindField(ctx, in.ProtoReflect(), b.body); err != nil {
+			return err
+		}
+	}
+	return ctx.BindVars(in)
+}
+
+// bindField decodes the body into a field of the request. The message fields are decoded
+// by the server decoder, the other fields can only be decoded from JSON.
+func bindField(ctx Context, m protoreflect.Message, fd protoreflect.FieldDescriptor) error {
+	if fd.Kind() == protoreflect.MessageKind && !fd.IsList() && !fd.IsMap() {
+		return ctx.Bind(m.Mutable(fd).Message().Interface())
+	}
+	codec, ok := CodecForRequest(ctx.Request(), "Content-Type")
+	if !ok || codec.Name() != "json" {
+		return errors.BadRequest("CODEC", fmt.Sprintf("unsupported Content-Type of the %s field: %s", fd.Name(), ctx.Request().Header.Get("Content-Type")))
+	}
+	data, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		if se := new(errors.Error); errors.As(err, &se) {
+			return se
+		}
+		return errors.BadRequest("CODEC", err.Error())
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	// the field is decoded into a new message, as the codec resets the message it decodes.
+	v := m.New()
+	if err = codec.Unmarshal([]byte(fmt.Sprintf("{%q:%s}", fd.JSONName(), data)), v.Interface()); err != nil {
+		return errors.BadRequest("CODEC", fmt.Sprintf("body unmarshal %s", err.Error()))
+	}
+	m.Set(fd, v.Get(fd))
+	return nil
+}
+
+// reply returns the response body of the reply, the whole reply without a response body field.
+func (b *ruleBinding) reply(r *http.Request, out proto.Message) (interface{}, error) {
+	if b.responseBody == nil {
+		return out, nil
+	}
+	return responseBody(r, out, b.responseBody)
+}
+
+// ResponseBody returns the response body of a reply whose response_body is one of its fields,
+// encoded as the routes of RegisterService encode it. It is used by the generated handlers.
+func ResponseBody(r *http.Request, reply proto.Message, field string) (interface{}, error) {
+	fd := reply.ProtoReflect().Descriptor().Fields().ByName(protoreflect.Name(field))
+	if fd == nil {
+		return nil, fmt.Errorf("http: response body field %s not found in %s", field, reply.ProtoReflect().Descriptor().FullName())
+	}
+	return responseBody(r, reply, fd)
+}
+
+// responseBody returns a field of the reply as the response body. The message fields are
+// encoded by the negotiated codec as the whole reply is. The other fields have no message
+// to encode, their JSON value is encoded by the negotiated codec instead, as a
+// google.protobuf.Value by the proto codec.
+func responseBody(r *http.Request, out proto.Message, fd protoreflect.FieldDescriptor) (interface{}, error) {
+	m := out.ProtoReflect()
+	if fd.Kind() == protoreflect.MessageKind && !fd.IsList() && !fd.IsMap() {
+		return m.Get(fd).Message().Interface(), nil
+	}
+	data, err := encoding.GetCodec("json").Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	value := fields[fd.JSONName()]
+	codec, _ := responseMediaType(r)
+	if codec.Name() == "json" {
+		return value, nil
+	}
+	var v interface{}
+	if len(value) > 0 {
+		if err = json.Unmarshal(value, &v); err != nil {
+			return nil, err
+		}
+	}
+	if codec.Name() == "proto" {
+		return structpb.NewValue(v)
+	}
+	return v, nil
+}
+
+// ReplyBody returns the value a client decodes the response body into when it is the
+// response_body field of the reply, such as the bodies encoded by ResponseBody. The
+// decoded body is set to the field of the reply. It is used by the generated clients.
+func ReplyBody(reply proto.Message, field string) interface{} {
+	return &replyBody{reply: reply, field: field}
+}
+
+type replyBody struct {
+	reply proto.Message
+	field string
+}
+
+func (b *replyBody) UnmarshalJSON(data []byte) error {
+	return b.unmarshal(encoding.GetCodec("json"), data)
+}
+
+// unmarshal decodes the body of a codec into the field of the reply, the inverse of responseBody.
+func (b *replyBody) unmarshal(codec encoding.Codec, data []byte) error {
+	m := b.reply.ProtoReflect()
+	fd := m.Descriptor().Fields().ByName(protoreflect.Name(b.field))
+	if fd == nil {
+		return fmt.Errorf("http: response body field %s not found in %s", b.field, m.Descriptor().FullName())
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if fd.Kind() == protoreflect.MessageKind && !fd.IsList() && !fd.IsMap() {
+		return codec.Unmarshal(data, m.Mutable(fd).Message().Interface())
+	}
+	value := data
+	switch codec.Name() {
+	case "json":
+	case "proto":
+		v := new(structpb.Value)
+		if err := codec.Unmarshal(data, v); err != nil {
+			return err
+		}
+		var err error
+		if value, err = json.Marshal(v.AsInterface()); err != nil {
+			return err
+		}
+	default:
+		var v interface{}
+		if err := codec.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		var err error
+		if value, err = json.Marshal(v); err != nil {
+			return err
+		}
+	}
+	// the field is decoded into a new message, as the codec resets the message it decodes.
+	v := m.New()
+	if err := encoding.GetCodec("json").Unmarshal([]byte(fmt.Sprintf("{%q:%s}", fd.JSONName(), value)), v.Interface()); err != nil {
+		return err
+	}
+	m.Set(fd, v.Get(fd))
+	return nil
+}
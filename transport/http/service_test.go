@@ -0,0 +1,359 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/JellyTony/zeus/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// echoFile describes the zeus.test.Echo service, whose methods reply with their request.
+var echoFile = mustEchoFile()
+
+func mustEchoFile() protoreflect.FileDescriptor {
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Type:     typ.Enum(),
+			Label:    label.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	method := func(name string, rule *annotations.HttpRule) *descriptorpb.MethodDescriptorProto {
+		m := &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String(name),
+			InputType:  proto.String(".zeus.test.Message"),
+			OutputType: proto.String(".zeus.test.Message"),
+			Options:    &descriptorpb.MethodOptions{},
+		}
+		if rule != nil {
+			proto.SetExtension(m.Options, annotations.E_Http, rule)
+		}
+		return m
+	}
+	optional, repeated := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("zeus/test/echo.proto"),
+		Package: proto.String("zeus.test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Message"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, ""),
+					field("name", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, ""),
+					field("sub", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, optional, ".zeus.test.Sub"),
+					field("tags", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING, repeated, ""),
+				},
+			},
+			{
+				Name: proto.String("Sub"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("value", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, ""),
+				},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Echo"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				method("Get", &annotations.HttpRule{
+					Pattern: &annotations.HttpRule_Get{Get: "/v1/messages/{id}"},
+					AdditionalBindings: []*annotations.HttpRule{
+						{Pattern: &annotations.HttpRule_Get{Get: "/v1/users/{name}/messages/{id}"}},
+					},
+				}),
+				method("Create", &annotations.HttpRule{
+					Pattern: &annotations.HttpRule_Post{Post: "/v1/messages"},
+					Body:    "*",
+				}),
+				method("Update", &annotations.HttpRule{
+					Pattern:      &annotations.HttpRule_Patch{Patch: "/v1/messages/{id}"},
+					Body:         "sub",
+					ResponseBody: "sub",
+				}),
+				method("Tag", &annotations.HttpRule{
+					Pattern: &annotations.HttpRule_Custom{Custom: &annotations.CustomHttpPattern{
+						Kind: http.MethodPut,
+						Path: "/v1/messages/{id}/tags",
+					}},
+					Body:         "tags",
+					ResponseBody: "tags",
+				}),
+				method("Cancel", &annotations.HttpRule{
+					Pattern: &annotations.HttpRule_Post{Post: "/v1/{name=messages/*}:cancel"},
+					Body:    "*",
+				}),
+				method("Ping", nil),
+			},
+		}},
+	}
+	fd, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+	if err != nil {
+		panic(err)
+	}
+	if err = protoregistry.GlobalFiles.RegisterFile(fd); err != nil {
+		panic(err)
+	}
+	return fd
+}
+
+type echoServer interface {
+	Echo(context.Context, proto.Message) (proto.Message, error)
+}
+
+type echoService struct{}
+
+func (echoService) Echo(_ context.Context, in proto.Message) (proto.Message, error) {
+	if in.ProtoReflect().Get(echoFile.Messages().ByName("Message").Fields().ByName("id")).String() == "missing" {
+		return nil, errors.NotFound("MESSAGE_NOT_FOUND", "message not found")
+	}
+	return in, nil
+}
+
+func echoMethod(name string) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := dynamicpb.NewMessage(echoFile.Messages().ByName("Message"))
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return srv.(echoServer).Echo(ctx, req.(proto.Message))
+			}
+			if interceptor == nil {
+				return handler(ctx, in)
+			}
+			return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/zeus.test.Echo/" + name}, handler)
+		},
+	}
+}
+
+var echoServiceDesc = grpc.ServiceDesc{
+	ServiceName: "zeus.test.Echo",
+	HandlerType: (*echoServer)(nil),
+	Methods: []grpc.MethodDesc{
+		echoMethod("Get"),
+		echoMethod("Create"),
+		echoMethod("Update"),
+		echoMethod("Tag"),
+		echoMethod("Cancel"),
+		echoMethod("Ping"),
+	},
+}
+
+func TestRegisterService(t *testing.T) {
+	var operations []string
+	srv := NewServer()
+	srv.Use("/zeus.test.Echo/*", func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if _, ok := req.(proto.Message); !ok {
+				t.Errorf("expected a decoded request got %T", req)
+			}
+			tr, _ := transport.FromServerContext(ctx)
+			operations = append(operations, tr.Operation())
+			return handler(ctx, req)
+		}
+	})
+	srv.RegisterService(&echoServiceDesc, echoService{})
+
+	tests := []struct {
+		method    string
+		path      string
+		body      string
+		code      int
+		reply     string
+		operation string
+	}{
+		{http.MethodGet, "/v1/messages/1?name=zeus&sub.value=v&tags=a&tags=b", "", 200, `{"id":"1","name":"zeus","sub":{"value":"v"},"tags":["a","b"]}`, "/zeus.test.Echo/Get"},
+		{http.MethodGet, "/v1/users/zeus/messages/1?name=kratos", "", 200, `{"id":"1","name":"zeus","sub":null,"tags":[]}`, "/zeus.test.Echo/Get"},
+		{http.MethodGet, "/v1/messages/missing", "", 404, "MESSAGE_NOT_FOUND", "/zeus.test.Echo/Get"},
+		{http.MethodPost, "/v1/messages?name=ignored", `{"id":"1","name":"zeus"}`, 200, `{"id":"1","name":"zeus","sub":null,"tags":[]}`, "/zeus.test.Echo/Create"},
+		{http.MethodPatch, "/v1/messages/1?sub.value=query", `{"value":"body"}`, 200, `{"value":"body"}`, "/zeus.test.Echo/Update"},
+		{http.MethodPut, "/v1/messages/1/tags", `["a","b"]`, 200, `["a","b"]`, "/zeus.test.Echo/Tag"},
+		{http.MethodPost, "/v1/messages/1:cancel", `{"id":"1"}`, 200, `{"id":"1","name":"messages/1","sub":null,"tags":[]}`, "/zeus.test.Echo/Cancel"},
+		{http.MethodPost, "/v1/messages", `{"id":`, 400, "CODEC", ""},
+		{http.MethodPost, "/zeus.test.Echo/Ping", `{}`, 404, "", ""},
+	}
+	for _, test := range tests {
+		operations = nil
+		req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		req.Header.Set("Content-Type", appJSONStr)
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, req)
+		if res.Code != test.code {
+			t.Errorf("%s %s: expected %d got %d", test.method, test.path, test.code, res.Code)
+		}
+		body, _ := io.ReadAll(res.Body)
+		if test.code == 200 && !jsonEqual(body, test.reply) {
+			t.Errorf("%s %s: expected %s got %s", test.method, test.path, test.reply, body)
+		}
+		if test.code != 200 && !strings.Contains(string(body), test.reply) {
+			t.Errorf("%s %s: expected %s in %s", test.method, test.path, test.reply, body)
+		}
+		if test.operation != "" && (len(operations) != 1 || operations[0] != test.operation) {
+			t.Errorf("%s %s: expected operation %s got %v", test.method, test.path, test.operation, operations)
+		}
+	}
+}
+
+func TestRegisterServiceResponseBody(t *testing.T) {
+	srv := NewServer()
+	srv.RegisterService(&echoServiceDesc, echoService{})
+
+	// the repeated field is encoded as a google.protobuf.Value by the proto codec.
+	tests := []struct {
+		accept string
+		reply  proto.Message
+		want   proto.Message
+	}{
+		{"application/x-protobuf", new(structpb.Value), structpb.NewListValue(&structpb.ListValue{Values: []*structpb.Value{
+			structpb.NewStringValue("a"), structpb.NewStringValue("b"),
+		}})},
+		{"application/json", nil, nil},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodPut, "/v1/messages/1/tags", strings.NewReader(`["a","b"]`))
+		req.Header.Set("Content-Type", appJSONStr)
+		req.Header.Set("Accept", test.accept)
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, req)
+		if res.Code != 200 {
+			t.Fatalf("%s: expected %d got %d %s", test.accept, 200, res.Code, res.Body.String())
+		}
+		if test.reply == nil {
+			if !jsonEqual(res.Body.Bytes(), `["a","b"]`) {
+				t.Errorf("%s: expected %s got %s", test.accept, `["a","b"]`, res.Body.String())
+			}
+			continue
+		}
+		if err := proto.Unmarshal(res.Body.Bytes(), test.reply); err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(test.reply, test.want) {
+			t.Errorf("%s: expected %v got %v", test.accept, test.want, test.reply)
+		}
+	}
+}
+
+func TestReplyBody(t *testing.T) {
+	srv := NewServer()
+	srv.RegisterService(&echoServiceDesc, echoService{})
+	md := echoFile.Messages().ByName("Message")
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		field  string
+		accept string
+		want   string
+	}{
+		{http.MethodPut, "/v1/messages/1/tags", `["a","b"]`, "tags", "application/json", `{"id":"","name":"","sub":null,"tags":["a","b"]}`},
+		{http.MethodPut, "/v1/messages/1/tags", `["a","b"]`, "tags", "application/x-protobuf", `{"id":"","name":"","sub":null,"tags":["a","b"]}`},
+		{http.MethodPatch, "/v1/messages/1", `{"value":"zeus"}`, "sub", "application/json", `{"id":"","name":"","sub":{"value":"zeus"},"tags":[]}`},
+		{http.MethodPatch, "/v1/messages/1", `{"value":"zeus"}`, "sub", "application/x-protobuf", `{"id":"","name":"","sub":{"value":"zeus"},"tags":[]}`},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		req.Header.Set("Content-Type", appJSONStr)
+		req.Header.Set("Accept", test.accept)
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, req)
+		reply := dynamicpb.NewMessage(md)
+		if err := DefaultResponseDecoder(context.Background(), res.Result(), ReplyBody(reply, test.field)); err != nil {
+			t.Fatalf("%s %s: %v", test.path, test.accept, err)
+		}
+		data, err := encoding.GetCodec("json").Marshal(reply)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !jsonEqual(data, test.want) {
+			t.Errorf("%s %s: expected %s got %s", test.path, test.accept, test.want, data)
+		}
+	}
+}
+
+// jsonEqual reports whether data and s are the same JSON value,
+// protojson randomizes the whitespace of its output.
+func jsonEqual(data []byte, s string) bool {
+	var a, b interface{}
+	if json.Unmarshal(data, &a) != nil || json.Unmarshal([]byte(s), &b) != nil {
+		return false
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+type greeterService struct {
+	helloworld.UnimplementedGreeterServer
+}
+
+func (greeterService) SayHello(_ context.Context, in *helloworld.HelloRequest) (*helloworld.HelloReply, error) {
+	return &helloworld.HelloReply{Message: "hello " + in.Name}, nil
+}
+
+func TestRegisterGeneratedService(t *testing.T) {
+	srv := NewServer()
+	helloworld.RegisterGreeterServer(srv, greeterService{})
+	var found bool
+	_ = srv.WalkRoute(func(info RouteInfo) error {
+		found = found || info.Method == http.MethodGet && info.Path == "/helloworld/:name"
+		return nil
+	})
+	if !found {
+		t.Errorf("expected the route %s", "GET /helloworld/:name")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/helloworld/zeus", nil)
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, req)
+	if res.Code != 200 || !jsonEqual(res.Body.Bytes(), `{"message":"hello zeus"}`) {
+		t.Errorf("expected %s got %d %s", `{"message":"hello zeus"}`, res.Code, res.Body.String())
+	}
+}
+
+func TestRegisterServiceInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		sd   *grpc.ServiceDesc
+		ss   interface{}
+	}{
+		{"handler", &echoServiceDesc, struct{}{}},
+		{"service", &grpc.ServiceDesc{ServiceName: "zeus.test.Missing", HandlerType: (*interface{})(nil)}, nil},
+		{"method", &grpc.ServiceDesc{ServiceName: "zeus.test.Echo", HandlerType: (*interface{})(nil), Methods: []grpc.MethodDesc{echoMethod("Missing")}}, nil},
+	}
+	for _, test := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected a panic", test.name)
+				}
+			}()
+			NewServer().RegisterService(test.sd, test.ss)
+		}()
+	}
+}
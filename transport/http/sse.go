@@ -0,0 +1,269 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+const defaultHeartbeat = 15 * time.Second
+
+// ErrStreamingUnsupported is returned when the response writer can not be flushed.
+var ErrStreamingUnsupported = errors.New("http: streaming unsupported by the response writer")
+
+// EventStreamOption is a server-sent event stream option.
+type EventStreamOption func(*EventStream)
+
+// Heartbeat with the interval of the keep-alive comments sent while the stream is idle,
+// default 15s, and zero disables them.
+func Heartbeat(interval time.Duration) EventStreamOption {
+	return func(s *EventStream) {
+		s.heartbeat = interval
+	}
+}
+
+// EventRetry with the reconnection time sent to the client when the stream starts.
+func EventRetry(d time.Duration) EventStreamOption {
+	return func(s *EventStream) {
+		s.retry = d
+	}
+}
+
+// EventStream is a server-sent event stream of a request. The events are flushed as
+// soon as they are sent, and the stream ends when the request context is done, so
+// it is bounded by the server timeout unless its route has been registered by Router.SSE.
+type EventStream struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	w           http.ResponseWriter
+	flusher     http.Flusher
+	enc         EncodeResponseFunc
+	req         *http.Request
+	lastEventID string
+	heartbeat   time.Duration
+	retry       time.Duration
+
+	mu     sync.Mutex
+	err    error
+	active chan struct{}
+	done   chan struct{}
+	once   sync.Once
+	wg     sync.WaitGroup
+}
+
+// SSE registers a new server-sent event stream route for a path with matching handler
+// in the router, the handler starts the stream with Context.EventStream. The route is
+// not bounded by the server timeout, the stream lasts until the client is gone, the
+// handler returns or the server shuts down.
+func (r *Router) SSE(path string, h HandlerFunc, m ...middleware.Middleware) {
+	r.handle(http.MethodGet, path, h, true, m...)
+}
+
+func newEventStream(ctx context.Context, w http.ResponseWriter, req *http.Request, enc EncodeResponseFunc, opts ...EventStreamOption) (*EventStream, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, ErrStreamingUnsupported
+	}
+	s := &EventStream{
+		ctx:         ctx,
+		w:           w,
+		flusher:     flusher,
+		enc:         enc,
+		req:         req,
+		lastEventID: req.Header.Get("Last-Event-ID"),
+		heartbeat:   defaultHeartbeat,
+		active:      make(chan struct{}, 1),
+		done:        make(chan struct{}),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	header := w.Header()
+	header.Set("Content-Type", ContentTypeEventStream)
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	var prelude bytes.Buffer
+	if s.retry > 0 {
+		prelude.WriteString("retry: " + strconv.FormatInt(s.retry.Milliseconds(), 10) + "\n\n")
+	}
+	if err := s.write(prelude.Bytes()); err != nil {
+		return nil, err
+	}
+	s.wg.Add(1)
+	go s.watch()
+	return s, nil
+}
+
+// LastEventID returns the Last-Event-ID sent by the client to resume the stream.
+func (s *EventStream) LastEventID() string {
+	return s.lastEventID
+}
+
+// Done returns a channel closed when the stream ends.
+func (s *EventStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the error that ended the stream.
+func (s *EventStream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Send encodes v with the response encoder of the server and sends it as an event.
+func (s *EventStream) Send(id, event string, v interface{}) error {
+	w := &eventWriter{header: make(http.Header)}
+	if err := s.enc(w, s.req, v); err != nil {
+		return err
+	}
+	return s.SendEvent(&Event{ID: id, Event: event, Data: w.buf.Bytes()})
+}
+
+// SendEvent sends the event and flushes it to the client.
+func (s *EventStream) SendEvent(e *Event) error {
+	var b bytes.Buffer
+	if e.ID != "" {
+		b.WriteString("id: " + oneLine(e.ID) + "\n")
+	}
+	if e.Event != "" {
+		b.WriteString("event: " + oneLine(e.Event) + "\n")
+	}
+	if e.Retry > 0 {
+		b.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+	for _, line := range bytes.Split(bytes.TrimRight(e.Data, "\n"), []byte("\n")) {
+		b.WriteString("data: ")
+		b.Write(bytes.TrimSuffix(line, []byte("\r")))
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	if err := s.write(b.Bytes()); err != nil {
+		return err
+	}
+	select {
+	case s.active <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Close ends the stream, it is called when the handler returns.
+func (s *EventStream) Close() error {
+	s.end(nil)
+	s.wg.Wait()
+	if s.cancel != nil {
+		s.cancel()
+	}
+	return nil
+}
+
+func (s *EventStream) end(err error) {
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+		close(s.done)
+	})
+}
+
+func (s *EventStream) write(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	select {
+	case <-s.done:
+		return context.Canceled
+	default:
+	}
+	if len(data) > 0 {
+		if _, err := s.w.Write(data); err != nil {
+			s.err = err
+			return err
+		}
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// watch sends the heartbeats, and ends the stream once the request context is done.
+func (s *EventStream) watch() {
+	defer s.wg.Done()
+	var tick <-chan time.Time
+	if s.heartbeat > 0 {
+		ticker := time.NewTicker(s.heartbeat)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	idle := true
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.ctx.Done():
+			s.end(s.ctx.Err())
+			return
+		case <-s.active:
+			idle = false
+		case <-tick:
+			if idle {
+				if err := s.write([]byte(": ping\n\n")); err != nil {
+					s.end(err)
+					return
+				}
+			}
+			idle = true
+		}
+	}
+}
+
+func oneLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// eventWriter buffers the encoded data of an event.
+type eventWriter struct {
+	header http.Header
+	buf    bytes.Buffer
+}
+
+func (w *eventWriter) Header() http.Header            { return w.header }
+func (w *eventWriter) WriteHeader(int)                {}
+func (w *eventWriter) Write(data []byte) (int, error) { return w.buf.Write(data) }
+
+func (s *Server) trackEventStream(es *EventStream) {
+	s.sseMu.Lock()
+	s.sseStreams[es] = struct{}{}
+	s.sseMu.Unlock()
+}
+
+func (s *Server) untrackEventStream(es *EventStream) {
+	s.sseMu.Lock()
+	delete(s.sseStreams, es)
+	s.sseMu.Unlock()
+}
+
+// closeEventStreams cancels the context of the event streams when the server shuts down,
+// so that their handlers return and the shutdown is not held by the open streams.
+func (s *Server) closeEventStreams() {
+	s.sseMu.Lock()
+	streams := make([]*EventStream, 0, len(s.sseStreams))
+	for es := range s.sseStreams {
+		streams = append(streams, es)
+	}
+	s.sseMu.Unlock()
+	for _, es := range streams {
+		es.cancel()
+	}
+}
@@ -0,0 +1,195 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newEventServer(t *testing.T, done chan error) *httptest.Server {
+	t.Helper()
+	srv := NewServer()
+	r := srv.Route("/")
+	r.SSE("/events", func(ctx Context) error {
+		s, err := ctx.EventStream(Heartbeat(20*time.Millisecond), EventRetry(time.Second))
+		if err != nil {
+			return err
+		}
+		from, _ := strconv.Atoi(s.LastEventID())
+		for i := from + 1; i <= 3; i++ {
+			if err := s.Send(strconv.Itoa(i), "user", &User{Name: "user" + strconv.Itoa(i)}); err != nil {
+				return err
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		return nil
+	})
+	r.SSE("/wait", func(ctx Context) error {
+		s, err := ctx.EventStream(Heartbeat(10 * time.Millisecond))
+		if err != nil {
+			return err
+		}
+		<-s.Done()
+		done <- s.Err()
+		return nil
+	})
+	return newTestServer(t, srv)
+}
+
+func TestEventStream(t *testing.T) {
+	ts := newEventServer(t, nil)
+	client, err := NewClient(context.Background(), WithEndpoint(strings.TrimPrefix(ts.URL, "http://")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := client.Events(context.Background(), "/events", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	for i := 2; i <= 3; i++ {
+		e, err := s.RecvEvent()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.ID != strconv.Itoa(i) || e.Event != "user" || string(e.Data) != `{"name":"user`+strconv.Itoa(i)+`"}` {
+			t.Errorf("unexpected event %+v", e)
+		}
+	}
+	if _, err := s.RecvEvent(); err != io.EOF {
+		t.Errorf("expected %v got %v", io.EOF, err)
+	}
+	if s.LastEventID() != "3" {
+		t.Errorf("expected %s got %s", "3", s.LastEventID())
+	}
+}
+
+func TestEventStreamHeartbeat(t *testing.T) {
+	ts := newEventServer(t, nil)
+	resp, err := http.Get(ts.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if v := resp.Header.Get("Content-Type"); v != ContentTypeEventStream {
+		t.Errorf("expected %s got %s", ContentTypeEventStream, v)
+	}
+	if v := resp.Header.Get("Cache-Control"); v != "no-cache" {
+		t.Errorf("expected %s got %s", "no-cache", v)
+	}
+	var lines []string
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	if lines[0] != "retry: 1000" {
+		t.Errorf("expected %s got %s", "retry: 1000", lines[0])
+	}
+	var pings int
+	for _, line := range lines {
+		if line == ": ping" {
+			pings++
+		}
+	}
+	if pings == 0 {
+		t.Errorf("expected heartbeats got %q", lines)
+	}
+}
+
+func TestEventStreamCancel(t *testing.T) {
+	done := make(chan error, 1)
+	ts := newEventServer(t, done)
+	client, err := NewClient(context.Background(), WithEndpoint(strings.TrimPrefix(ts.URL, "http://")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s, err := client.Events(ctx, "/wait", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("expected %v got %v", context.Canceled, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the stream to end once the client is gone")
+	}
+}
+
+func TestEventStreamTimeout(t *testing.T) {
+	srv := NewServer()
+	deadline := func(ctx Context) error {
+		_, ok := ctx.Request().Context().Deadline()
+		return ctx.String(200, strconv.FormatBool(ok))
+	}
+	r := srv.Route("/")
+	r.SSE("/events", deadline)
+	r.GET("/users", deadline)
+
+	tests := map[string]string{
+		"/events": "false",
+		"/users":  "true",
+	}
+	for path, want := range tests {
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, httptest.NewRequest(http.MethodGet, path, nil))
+		if got := res.Body.String(); got != want {
+			t.Errorf("%s: expected deadline %s got %s", path, want, got)
+		}
+	}
+}
+
+func TestEventStreamShutdown(t *testing.T) {
+	done := make(chan error, 1)
+	srv := NewServer(Address("127.0.0.1:0"))
+	srv.Route("/").SSE("/wait", func(ctx Context) error {
+		s, err := ctx.EventStream()
+		if err != nil {
+			return err
+		}
+		<-ctx.Done()
+		<-s.Done()
+		done <- s.Err()
+		return nil
+	})
+	e := startTestServer(t, srv)
+	client, err := NewClient(context.Background(), WithEndpoint(e.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := client.Events(context.Background(), "/wait", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	// the open stream neither holds the shutdown nor is aborted.
+	if err = srv.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if srv.Aborted() != 0 {
+		t.Errorf("expected %d got %d", 0, srv.Aborted())
+	}
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("expected %v got %v", context.Canceled, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the stream to end when the server shuts down")
+	}
+	if _, err := s.RecvEvent(); err != io.EOF {
+		t.Errorf("expected %v got %v", io.EOF, err)
+	}
+}
@@ -0,0 +1,278 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+)
+
+const (
+	// ContentTypeNDJSON is the content type of newline delimited JSON streams.
+	ContentTypeNDJSON = "application/x-ndjson"
+	// ContentTypeEventStream is the content type of server-sent event streams.
+	ContentTypeEventStream = "text/event-stream"
+	// ContentTypeProtobufDelimited is the content type of varint length delimited protobuf streams.
+	ContentTypeProtobufDelimited = "application/x-protobuf; delimited=true"
+
+	defaultMaxStreamMessageSize = 4 << 20
+)
+
+// Event is a server-sent event.
+type Event struct {
+	// ID is the event id, it is sent back as Last-Event-ID when the stream is resumed.
+	ID string
+	// Event is the event type.
+	Event string
+	// Data is the event data, the data lines are joined with "\n".
+	Data []byte
+	// Retry is the reconnection time requested by the server.
+	Retry time.Duration
+}
+
+// ClientStream is a streaming response, whose messages are decoded one at a time
+// without buffering the whole body. The stream format is taken from the response
+// content type: NDJSON, server-sent events or varint delimited protobuf.
+type ClientStream struct {
+	res         *http.Response
+	reader      *bufio.Reader
+	codec       encoding.Codec
+	next        func() ([]byte, error)
+	lastEventID string
+	maxSize     int
+}
+
+func newClientStream(res *http.Response) (*ClientStream, error) {
+	s := &ClientStream{
+		res:     res,
+		reader:  bufio.NewReader(res.Body),
+		maxSize: defaultMaxStreamMessageSize,
+	}
+	mediaType, params, err := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid stream content type: %w", err)
+	}
+	switch {
+	case mediaType == ContentTypeNDJSON || mediaType == "application/jsonl":
+		s.codec, s.next = encoding.GetCodec("json"), s.nextLine
+	case mediaType == ContentTypeEventStream:
+		s.codec, s.next = encoding.GetCodec("json"), s.nextEventData
+	case (mediaType == "application/x-protobuf" || mediaType == "application/protobuf") && params["delimited"] == "true":
+		s.codec, s.next = encoding.GetCodec("proto"), s.nextDelimited
+	default:
+		return nil, fmt.Errorf("unsupported stream content type: %s", mediaType)
+	}
+	return s, nil
+}
+
+// Header returns the response header.
+func (s *ClientStream) Header() http.Header {
+	return s.res.Header
+}
+
+// Recv decodes the next message of the stream into v,
+// it returns io.EOF at the end of the stream.
+func (s *ClientStream) Recv(v interface{}) error {
+	data, err := s.next()
+	if err != nil {
+		return err
+	}
+	return s.codec.Unmarshal(data, v)
+}
+
+// RecvEvent returns the next event of a server-sent event stream,
+// it returns io.EOF at the end of the stream.
+func (s *ClientStream) RecvEvent() (*Event, error) {
+	return readEvent(s.reader, &s.lastEventID, s.maxSize)
+}
+
+// LastEventID returns the id of the last event received from a server-sent event stream.
+func (s *ClientStream) LastEventID() string {
+	return s.lastEventID
+}
+
+// Close closes the response body.
+func (s *ClientStream) Close() error {
+	return s.res.Body.Close()
+}
+
+func (s *ClientStream) nextLine() ([]byte, error) {
+	for {
+		line, err := readLine(s.reader, s.maxSize)
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			return line, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (s *ClientStream) nextEventData() ([]byte, error) {
+	e, err := readEvent(s.reader, &s.lastEventID, s.maxSize)
+	if err != nil {
+		return nil, err
+	}
+	return e.Data, nil
+}
+
+func (s *ClientStream) nextDelimited() ([]byte, error) {
+	size, err := binary.ReadUvarint(s.reader)
+	if err != nil {
+		return nil, err
+	}
+	if size > uint64(s.maxSize) {
+		return nil, fmt.Errorf("stream message size %d exceeds the limit %d", size, s.maxSize)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(s.reader, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// readLine reads the next line of r, it fails once the line exceeds maxSize
+// instead of buffering it whole.
+func readLine(r *bufio.Reader, maxSize int) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if len(line)+len(chunk) > maxSize+1 {
+			return nil, fmt.Errorf("stream message size exceeds the limit %d", maxSize)
+		}
+		line = append(line, chunk...)
+		if err != bufio.ErrBufferFull {
+			return line, err
+		}
+	}
+}
+
+// readEvent reads the next event dispatched by a server-sent event stream,
+// the comments and the events without data are skipped.
+func readEvent(r *bufio.Reader, lastEventID *string, maxSize int) (*Event, error) {
+	var (
+		e    Event
+		data [][]byte
+		size int
+	)
+	for {
+		line, err := readLine(r, maxSize)
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return nil, err
+		}
+		line = bytes.TrimRight(line, "\r\n")
+		if len(line) == 0 {
+			if len(data) == 0 {
+				e = Event{}
+				continue
+			}
+			e.ID = *lastEventID
+			e.Data = bytes.Join(data, []byte("\n"))
+			return &e, nil
+		}
+		if line[0] == ':' {
+			continue
+		}
+		field, value := line, []byte(nil)
+		if i := bytes.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], bytes.TrimPrefix(line[i+1:], []byte(" "))
+		}
+		switch string(field) {
+		case "event":
+			e.Event = string(value)
+		case "data":
+			if size += len(value) + 1; size > maxSize+1 {
+				return nil, fmt.Errorf("stream message size exceeds the limit %d", maxSize)
+			}
+			data = append(data, value)
+		case "id":
+			if !bytes.ContainsRune(value, 0) {
+				*lastEventID = string(value)
+			}
+		case "retry":
+			if ms, err := strconv.ParseInt(string(value), 10, 64); err == nil {
+				e.Retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+// InvokeStream makes a call whose response is streamed. The args are encoded as Invoke
+// does, or sent as is when they are an io.Reader, so the request body is streamed too.
+// The stream must be closed by the caller.
+//
+// Neither the client timeout nor the retry and hedging policies apply to streams,
+// the call is bounded by the deadline of ctx instead.
+func (client *Client) InvokeStream(ctx context.Context, method, path string, args interface{}, opts ...CallOption) (*ClientStream, error) {
+	accept := strings.Join([]string{ContentTypeNDJSON, ContentTypeEventStream, ContentTypeProtobufDelimited}, ", ")
+	return client.invokeStream(ctx, method, path, args, http.Header{"Accept": {accept}}, opts...)
+}
+
+// Events subscribes to the server-sent event stream of path, it is resumed
+// after lastEventID when it is not empty. The stream must be closed by the caller.
+func (client *Client) Events(ctx context.Context, path, lastEventID string, opts ...CallOption) (*ClientStream, error) {
+	header := http.Header{"Accept": {ContentTypeEventStream}}
+	if lastEventID != "" {
+		header.Set("Last-Event-ID", lastEventID)
+	}
+	s, err := client.invokeStream(ctx, http.MethodGet, path, nil, header, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.lastEventID = lastEventID
+	return s, nil
+}
+
+func (client *Client) invokeStream(ctx context.Context, method, path string, args interface{}, header http.Header, opts ...CallOption) (*ClientStream, error) {
+	c := client.newCallInfo(path)
+	for _, o := range opts {
+		if err := o.before(&c); err != nil {
+			return nil, err
+		}
+	}
+	// the retry and hedging call options do not apply to streams either.
+	c.retry, c.hedging = nil, nil
+	req, err := client.newRequest(ctx, method, path, args, c)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	reply, err := client.roundTrip(newStreamContext(ctx), req, args, c, opts, func(_ context.Context, res *http.Response) (interface{}, error) {
+		s, err := newClientStream(res)
+		if err != nil {
+			res.Body.Close()
+			return nil, err
+		}
+		return s, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reply.(*ClientStream), nil
+}
+
+type streamKey struct{}
+
+// newStreamContext marks the requests of a stream, which are sent without the client timeout.
+func newStreamContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamKey{}, true)
+}
+
+func isStream(ctx context.Context) bool {
+	v, _ := ctx.Value(streamKey{}).(bool)
+	return v
+}
@@ -0,0 +1,231 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JellyTony/zeus/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+func newStreamServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ndjson", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentTypeNDJSON)
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(w, "{\"name\":\"user%d\"}\n\n", i)
+			w.(http.Flusher).Flush()
+			time.Sleep(50 * time.Millisecond)
+		}
+	})
+	mux.HandleFunc("/sse", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentTypeEventStream)
+		_, _ = io.WriteString(w, ": heartbeat\n\nretry: 1000\n\n")
+		_, _ = io.WriteString(w, "id: 1\nevent: user\ndata: {\"name\":\n")
+		_, _ = io.WriteString(w, "data: \"user0\"}\r\n\r\n")
+		_, _ = io.WriteString(w, "id: 2\ndata:{\"name\":\"user1\"}\n\n")
+	})
+	mux.HandleFunc("/proto", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentTypeProtobufDelimited)
+		for i := 0; i < 3; i++ {
+			data, _ := proto.Marshal(&helloworld.HelloReply{Message: fmt.Sprintf("hello%d", i)})
+			buf := make([]byte, binary.MaxVarintLen64)
+			_, _ = w.Write(buf[:binary.PutUvarint(buf, uint64(len(data)))])
+			_, _ = w.Write(data)
+		}
+	})
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", ContentTypeNDJSON)
+		fmt.Fprintf(w, "{\"name\":\"%s:%d:%d\"}\n", r.Header.Get("Content-Type"), r.ContentLength, len(data))
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+	})
+	mux.HandleFunc("/error", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"reason":"USER_NOT_FOUND"}`)
+	})
+	return newTestServer(t, mux)
+}
+
+func TestInvokeStream(t *testing.T) {
+	srv := newStreamServer(t)
+	// the client timeout is shorter than the streams.
+	client, err := NewClient(context.Background(), WithEndpoint(strings.TrimPrefix(srv.URL, "http://")), WithTimeout(60*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/ndjson", "/sse"} {
+		s, err := client.InvokeStream(context.Background(), http.MethodGet, path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for {
+			var u User
+			if err := s.Recv(&u); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s: %v", path, err)
+			}
+			names = append(names, u.Name)
+		}
+		s.Close()
+		if len(names) < 2 || names[0] != "user0" || names[1] != "user1" {
+			t.Errorf("%s: expected [user0 user1 ...] got %v", path, names)
+		}
+	}
+
+	s, err := client.InvokeStream(context.Background(), http.MethodGet, "/proto", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	for i := 0; ; i++ {
+		var reply helloworld.HelloReply
+		if err := s.Recv(&reply); err == io.EOF {
+			if i != 3 {
+				t.Errorf("expected %d messages got %d", 3, i)
+			}
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf("hello%d", i); reply.Message != want {
+			t.Errorf("expected %s got %s", want, reply.Message)
+		}
+	}
+}
+
+func TestInvokeStreamEvent(t *testing.T) {
+	srv := newStreamServer(t)
+	client, err := NewClient(context.Background(), WithEndpoint(strings.TrimPrefix(srv.URL, "http://")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := client.InvokeStream(context.Background(), http.MethodGet, "/sse", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	e, err := s.RecvEvent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.ID != "1" || e.Event != "user" || string(e.Data) != "{\"name\":\n\"user0\"}" || e.Retry != 0 {
+		t.Errorf("unexpected event %+v", e)
+	}
+	if e, err = s.RecvEvent(); err != nil {
+		t.Fatal(err)
+	}
+	if e.ID != "2" || e.Event != "" || string(e.Data) != `{"name":"user1"}` {
+		t.Errorf("unexpected event %+v", e)
+	}
+	if s.LastEventID() != "2" {
+		t.Errorf("expected %s got %s", "2", s.LastEventID())
+	}
+	if _, err = s.RecvEvent(); err != io.EOF {
+		t.Errorf("expected %v got %v", io.EOF, err)
+	}
+}
+
+func TestInvokeStreamError(t *testing.T) {
+	srv := newStreamServer(t)
+	client, err := NewClient(context.Background(), WithEndpoint(strings.TrimPrefix(srv.URL, "http://")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.InvokeStream(context.Background(), http.MethodGet, "/error", nil)
+	if errors.Reason(err) != "USER_NOT_FOUND" {
+		t.Errorf("expected %s got %v", "USER_NOT_FOUND", err)
+	}
+	if _, err = client.InvokeStream(context.Background(), http.MethodGet, "/plain", nil); err == nil {
+		t.Error("expected unsupported content type error")
+	}
+}
+
+func TestInvokeStreamRetry(t *testing.T) {
+	srv := newTestUpstream(t, 0, -1, http.StatusServiceUnavailable, "")
+	client, err := NewClient(context.Background(), WithEndpoint(strings.TrimPrefix(srv.URL, "http://")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// neither the retry nor the hedging call option is applied to the stream.
+	_, err = client.InvokeStream(context.Background(), http.MethodGet, "/users", nil,
+		Retry(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}),
+		Hedging(HedgingPolicy{MaxAttempts: 3, Delay: time.Millisecond}),
+	)
+	if !errors.IsServiceUnavailable(err) {
+		t.Errorf("expected %d got %v", http.StatusServiceUnavailable, err)
+	}
+	if n := srv.Calls(); n != 1 {
+		t.Errorf("expected %d calls got %d", 1, n)
+	}
+}
+
+func TestClientStreamMaxSize(t *testing.T) {
+	tests := []struct {
+		contentType string
+		body        string
+		valid       bool
+	}{
+		{ContentTypeNDJSON, "{\"name\":\"zeus\"}\n", true},
+		{ContentTypeNDJSON, "{\"name\":\"" + strings.Repeat("zeus", 8) + "\"}\n", false},
+		{ContentTypeNDJSON, "{\"name\":\"" + strings.Repeat("zeus", 8) + "\"}", false},
+		{ContentTypeEventStream, "data: {\"name\":\"zeus\"}\n\n", true},
+		{ContentTypeEventStream, "data: {\"name\":\n" + strings.Repeat("data: \"zeus\"\n", 4) + "\n", false},
+		{ContentTypeEventStream, "data: " + strings.Repeat("zeus", 8) + "\n\n", false},
+	}
+	for _, test := range tests {
+		s, err := newClientStream(&http.Response{
+			Header: http.Header{"Content-Type": {test.contentType}},
+			Body:   io.NopCloser(strings.NewReader(test.body)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.reader = bufio.NewReaderSize(s.res.Body, 16)
+		s.maxSize = 24
+		var u User
+		if err = s.Recv(&u); (err == nil) != test.valid {
+			t.Errorf("%q: expected valid %v got %v", test.body, test.valid, err)
+		}
+	}
+}
+
+func TestInvokeReader(t *testing.T) {
+	srv := newStreamServer(t)
+	client, err := NewClient(context.Background(), WithEndpoint(strings.TrimPrefix(srv.URL, "http://")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		w := bufio.NewWriter(pw)
+		for i := 0; i < 1000; i++ {
+			_, _ = w.WriteString("0123456789")
+		}
+		_ = w.Flush()
+		_ = pw.Close()
+	}()
+	var reply User
+	if err = client.Invoke(context.Background(), http.MethodPost, "/upload", pr, &reply, ContentType("application/octet-stream")); err != nil {
+		t.Fatal(err)
+	}
+	// the body of unknown length is sent chunked.
+	if want := "application/octet-stream:-1:10000"; reply.Name != want {
+		t.Errorf("expected %s got %s", want, reply.Name)
+	}
+}
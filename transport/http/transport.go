@@ -24,6 +24,7 @@ type Transport struct {
 	replyHeader  headerCarrier
 	request      *http.Request
 	pathTemplate string
+	peer         *Peer
 }
 
 // Kind returns the transport kind.
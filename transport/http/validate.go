@@ -0,0 +1,264 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const reasonValidator = "VALIDATOR"
+
+// ValidatorOption is a validator middleware option.
+type ValidatorOption func(*validatorOptions)
+
+type validatorOptions struct {
+	validate *validator.Validate
+}
+
+// StructValidator with the validator of the go-playground validate tags of the Go structs,
+// such as one with custom validations. The violations are keyed by the field names it
+// reports, the default validator reports the names of the json tags.
+func StructValidator(v *validator.Validate) ValidatorOption {
+	return func(o *validatorOptions) {
+		o.validate = v
+	}
+}
+
+// validateAller is implemented by the messages generated by protoc-gen-validate,
+// ValidateAll reports all the violations instead of the first one.
+type validateAller interface {
+	ValidateAll() error
+}
+
+type validatable interface {
+	Validate() error
+}
+
+// fieldViolation is implemented by the validation errors of protoc-gen-validate.
+type fieldViolation interface {
+	Field() string
+	Reason() string
+	Cause() error
+}
+
+// multiViolation is implemented by the multi errors of protoc-gen-validate.
+type multiViolation interface {
+	AllErrors() []error
+}
+
+// Validator returns a middleware that validates the requests once they are bound.
+// The proto messages are validated by their generated ValidateAll or Validate rules,
+// and the Go structs by their go-playground validate tags then their Validate method.
+// Invalid requests are rejected with a BadRequest error, whose metadata maps the
+// path of each invalid field, such as "profile.nickname", to its violation, and
+// whose errdetails.BadRequest detail lists the same field violations.
+//
+// The middleware is installed on the server, by the Middleware option or Server.Use,
+// and validates the decoded requests passed to the middleware chain by the generated
+// handlers, RegisterService and ctx.Middleware. The server and router chains also run
+// with the *http.Request before it is bound, which is passed through as is, so the
+// middleware validates nothing once installed on a router or a route. Bind, BindQuery
+// and BindVars do not validate, the hand-written handlers use BindValid or Validate.
+func Validator(opts ...ValidatorOption) middleware.Middleware {
+	o := &validatorOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.validate == nil {
+		o.validate = defaultStructValidator()
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if err := validateRequest(o.validate, req); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+var (
+	defaultValidateOnce sync.Once
+	defaultValidate     *validator.Validate
+)
+
+// Validate validates req as the Validator middleware does, such as once it is bound
+// by BindQuery or BindVars.
+func Validate(req interface{}, opts ...ValidatorOption) error {
+	o := &validatorOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.validate == nil {
+		// the validator caches the parsed structs, it is shared by the calls.
+		defaultValidateOnce.Do(func() {
+			defaultValidate = defaultStructValidator()
+		})
+		o.validate = defaultValidate
+	}
+	return validateRequest(o.validate, req)
+}
+
+// BindValid binds the request body to v with ctx.Bind then validates it with Validate,
+// with the options given rather than those of the Validator installed on the server.
+func BindValid(ctx Context, v interface{}, opts ...ValidatorOption) error {
+	if err := ctx.Bind(v); err != nil {
+		return err
+	}
+	return Validate(v, opts...)
+}
+
+func defaultStructValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		switch name {
+		case "-":
+			return ""
+		case "":
+			return f.Name
+		}
+		return name
+	})
+	return v
+}
+
+func validateRequest(v *validator.Validate, req interface{}) error {
+	switch req.(type) {
+	case nil, *http.Request:
+		// the request is not bound yet.
+		return nil
+	}
+	m, isProto := req.(proto.Message)
+	if !isProto {
+		if rv := reflect.Indirect(reflect.ValueOf(req)); rv.Kind() == reflect.Struct {
+			if err := v.Struct(req); err != nil {
+				return validationError(err, nil)
+			}
+		}
+	}
+	var err error
+	switch r := req.(type) {
+	case validateAller:
+		err = r.ValidateAll()
+	case validatable:
+		err = r.Validate()
+	}
+	if err == nil {
+		return nil
+	}
+	var md protoreflect.MessageDescriptor
+	if isProto {
+		md = m.ProtoReflect().Descriptor()
+	}
+	return validationError(err, md)
+}
+
+// validationError converts the violations of err to a BadRequest error with
+// the field violations in its metadata and in an errdetails.BadRequest detail,
+// the fields of the proto messages are reported by their JSON names.
+func validationError(err error, md protoreflect.MessageDescriptor) error {
+	fields := make(map[string]string)
+	collectViolations(err, "", fields)
+	if md != nil {
+		for field, reason := range fields {
+			if name := protoFieldPath(md, field); name != field {
+				delete(fields, field)
+				fields[name] = reason
+			}
+		}
+	}
+	e := errors.BadRequest(reasonValidator, err.Error()).WithCause(err)
+	if len(fields) == 0 {
+		return e
+	}
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(names))
+	for _, field := range names {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: field, Description: fields[field]})
+	}
+	return WithDetails(e.WithMetadata(fields), &errdetails.BadRequest{FieldViolations: violations})
+}
+
+// collectViolations adds the violations of err to fields, the violations of
+// the embedded messages are flattened to the paths of their fields.
+func collectViolations(err error, prefix string, fields map[string]string) {
+	switch e := err.(type) {
+	case validator.ValidationErrors:
+		for _, fe := range e {
+			// the namespace starts with the name of the struct type.
+			field := fe.Namespace()
+			if i := strings.Index(field, "."); i >= 0 {
+				field = field[i+1:]
+			}
+			rule := fe.Tag()
+			if fe.Param() != "" {
+				rule += "=" + fe.Param()
+			}
+			fields[prefix+field] = rule
+		}
+	case multiViolation:
+		for _, err := range e.AllErrors() {
+			collectViolations(err, prefix, fields)
+		}
+	case fieldViolation:
+		field := prefix + e.Field()
+		switch cause := e.Cause(); cause.(type) {
+		case fieldViolation, multiViolation:
+			collectViolations(cause, field+".", fields)
+		default:
+			fields[field] = e.Reason()
+		}
+	}
+}
+
+// protoFieldPath converts a path of the Go field names of a message, such as
+// "Profile.Tags[0]", to the path of their JSON names, such as "profile.tags[0]".
+func protoFieldPath(md protoreflect.MessageDescriptor, path string) string {
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		if md == nil {
+			break
+		}
+		name, index := part, ""
+		if j := strings.Index(part, "["); j >= 0 {
+			name, index = part[:j], part[j:]
+		}
+		fd := goField(md, name)
+		if fd == nil {
+			break
+		}
+		parts[i] = fd.JSONName() + index
+		md = fd.Message()
+		if fd.IsMap() {
+			md = fd.MapValue().Message()
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// goField returns the field of a message by its Go name, the camel case of its proto name.
+func goField(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if strings.EqualFold(strings.ReplaceAll(string(fd.Name()), "_", ""), name) {
+			return fd
+		}
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+type signupAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type signupRequest struct {
+	Name    string         `json:"name" validate:"required,min=3"`
+	Age     int            `json:"age" validate:"gte=18"`
+	Email   string         `validate:"omitempty,email"`
+	Address *signupAddress `json:"address" validate:"required"`
+}
+
+// violation is a validation error as generated by protoc-gen-validate.
+type violation struct {
+	field  string
+	reason string
+	cause  error
+}
+
+func (v violation) Field() string  { return v.field }
+func (v violation) Reason() string { return v.reason }
+func (v violation) Cause() error   { return v.cause }
+func (v violation) Error() string  { return fmt.Sprintf("invalid %s: %s", v.field, v.reason) }
+
+type multiError []error
+
+func (m multiError) AllErrors() []error { return m }
+func (m multiError) Error() string      { return fmt.Sprintf("%d violations", len(m)) }
+
+// validatedMessage is a proto message with the rules of protoc-gen-validate.
+type validatedMessage struct {
+	*dynamicpb.Message
+	err error
+}
+
+func (m validatedMessage) Validate() error    { return violation{field: "Id", reason: "first only"} }
+func (m validatedMessage) ValidateAll() error { return m.err }
+
+type validatedStruct struct {
+	Name string `json:"name" validate:"required"`
+}
+
+func (validatedStruct) Validate() error { return violation{field: "Name", reason: "reserved"} }
+
+func TestValidator(t *testing.T) {
+	message := func(err error) validatedMessage {
+		return validatedMessage{Message: dynamicpb.NewMessage(echoFile.Messages().ByName("Message")), err: err}
+	}
+	tests := []struct {
+		name   string
+		req    interface{}
+		code   int
+		fields map[string]string
+	}{
+		{"nil", nil, 200, nil},
+		{"valid struct", &signupRequest{Name: "zeus", Age: 18, Address: &signupAddress{City: "Athens"}}, 200, nil},
+		{"invalid struct", &signupRequest{Name: "ze", Age: 3, Email: "zeus", Address: &signupAddress{}}, 400, map[string]string{
+			"name":         "min=3",
+			"age":          "gte=18",
+			"Email":        "email",
+			"address.city": "required",
+		}},
+		{"missing struct", &signupRequest{Name: "zeus", Age: 18}, 400, map[string]string{"address": "required"}},
+		{"struct value", signupRequest{Name: "zeus", Age: 18}, 400, map[string]string{"address": "required"}},
+		{"nil struct", (*signupRequest)(nil), 200, nil},
+		{"struct method", &validatedStruct{Name: "admin"}, 400, map[string]string{"Name": "reserved"}},
+		{"struct tags before method", &validatedStruct{}, 400, map[string]string{"name": "required"}},
+		{"valid message", message(nil), 200, nil},
+		{"invalid message", message(multiError{
+			violation{field: "Id", reason: "value length must be at least 1 runes"},
+			violation{field: "Sub", reason: "embedded message failed validation", cause: multiError{
+				violation{field: "Value", reason: "value must be a valid email"},
+			}},
+			violation{field: "Tags[1]", reason: "value must not be empty"},
+		}), 400, map[string]string{
+			"id":        "value length must be at least 1 runes",
+			"sub.value": "value must be a valid email",
+			"tags[1]":   "value must not be empty",
+		}},
+		{"plain error", message(errors.New(400, "", "invalid")), 400, nil},
+	}
+	h := Validator()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	for _, test := range tests {
+		_, err := h(context.Background(), test.req)
+		if code := errors.Code(err); code != test.code {
+			t.Errorf("%s: expected %d got %d", test.name, test.code, code)
+		}
+		if err == nil {
+			continue
+		}
+		if reason := errors.Reason(err); reason != reasonValidator {
+			t.Errorf("%s: expected %s got %s", test.name, reasonValidator, reason)
+		}
+		if fields := errors.FromError(err).Metadata; (len(fields) > 0 || len(test.fields) > 0) && !reflect.DeepEqual(fields, test.fields) {
+			t.Errorf("%s: expected %v got %v", test.name, test.fields, fields)
+		}
+	}
+}
+
+func TestStructValidator(t *testing.T) {
+	v := validator.New()
+	_ = v.RegisterValidation("zeus", func(fl validator.FieldLevel) bool {
+		return fl.Field().String() == "zeus"
+	})
+	h := Validator(StructValidator(v))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	req := &struct {
+		Name string `validate:"zeus"`
+	}{Name: "kratos"}
+	_, err := h(context.Background(), req)
+	want := map[string]string{"Name": "zeus"}
+	if fields := errors.FromError(err).Metadata; !reflect.DeepEqual(fields, want) {
+		t.Errorf("expected %v got %v", want, fields)
+	}
+}
+
+func TestValidatorServer(t *testing.T) {
+	srv := NewServer(Middleware(Validator()))
+	srv.Route("/").POST("/signup", func(ctx Context) error {
+		var in signupRequest
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return req, nil
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		return ctx.Result(200, out)
+	})
+	tests := []struct {
+		body   string
+		code   int
+		fields map[string]string
+	}{
+		{`{"name":"zeus","age":20,"address":{"city":"Athens"}}`, 200, nil},
+		{`{"name":"zeus","age":10,"address":{}}`, 400, map[string]string{"age": "gte=18", "address.city": "required"}},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(test.body))
+		req.Header.Set("Content-Type", appJSONStr)
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, req)
+		if res.Code != test.code {
+			t.Errorf("%s: expected %d got %d", test.body, test.code, res.Code)
+		}
+		if test.code == 200 {
+			continue
+		}
+		var status struct {
+			Reason   string            `json:"reason"`
+			Metadata map[string]string `json:"metadata"`
+		}
+		if err := json.Unmarshal(res.Body.Bytes(), &status); err != nil {
+			t.Fatal(err)
+		}
+		if status.Reason != reasonValidator || !reflect.DeepEqual(status.Metadata, test.fields) {
+			t.Errorf("%s: expected %s %v got %s %v", test.body, reasonValidator, test.fields, status.Reason, status.Metadata)
+		}
+	}
+}
+
+func TestBindValid(t *testing.T) {
+	srv := NewServer()
+	r := srv.Route("/")
+	r.POST("/signup", func(ctx Context) error {
+		var in signupRequest
+		if err := BindValid(ctx, &in); err != nil {
+			return err
+		}
+		return ctx.Result(200, &in)
+	})
+	r.GET("/signup", func(ctx Context) error {
+		var in signupRequest
+		if err := ctx.BindQuery(&in); err != nil {
+			return err
+		}
+		if err := Validate(&in); err != nil {
+			return err
+		}
+		return ctx.Result(200, &in)
+	})
+	tests := []struct {
+		method string
+		target string
+		body   string
+		code   int
+		fields map[string]string
+	}{
+		{http.MethodPost, "/signup", `{"name":"zeus","age":20,"address":{"city":"Athens"}}`, 200, nil},
+		{http.MethodPost, "/signup", `{"name":"zeus","age":10,"address":{}}`, 400, map[string]string{"age": "gte=18", "address.city": "required"}},
+		{http.MethodPost, "/signup", `{"name":"ze",`, 400, nil},
+		{http.MethodGet, "/signup?name=ze&age=20", "", 400, map[string]string{"name": "min=3", "address": "required"}},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.target, strings.NewReader(test.body))
+		req.Header.Set("Content-Type", appJSONStr)
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, req)
+		if res.Code != test.code {
+			t.Errorf("%s %s: expected %d got %d", test.target, test.body, test.code, res.Code)
+		}
+		if test.fields == nil {
+			continue
+		}
+		var status struct {
+			Reason   string            `json:"reason"`
+			Metadata map[string]string `json:"metadata"`
+		}
+		if err := json.Unmarshal(res.Body.Bytes(), &status); err != nil {
+			t.Fatal(err)
+		}
+		if status.Reason != reasonValidator || !reflect.DeepEqual(status.Metadata, test.fields) {
+			t.Errorf("%s %s: expected %s %v got %s %v", test.target, test.body, reasonValidator, test.fields, status.Reason, status.Metadata)
+		}
+	}
+}
+
+// namedMessage is a zeus.test.Message whose name is required.
+type namedMessage struct {
+	*dynamicpb.Message
+}
+
+func (m namedMessage) Validate() error {
+	if m.Get(m.Descriptor().Fields().ByName("name")).String() == "" {
+		return violation{field: "Name", reason: "value is required"}
+	}
+	return nil
+}
+
+func TestValidatorRoutes(t *testing.T) {
+	v := validator.New()
+	// the struct validations would reject the *http.Request of the server chain.
+	v.RegisterStructValidation(func(sl validator.StructLevel) {
+		sl.ReportError(sl.Current().Interface(), "Request", "Request", "bound", "")
+	}, http.Request{})
+	srv := NewServer(Middleware(Validator(StructValidator(v))))
+	r := srv.Route("/")
+	r.GET("/health", func(ctx Context) error {
+		return ctx.String(200, "ok")
+	})
+	r.POST("/signup", func(ctx Context) error {
+		var in signupRequest
+		if err := BindValid(ctx, &in); err != nil {
+			return err
+		}
+		return ctx.Result(200, &in)
+	})
+	desc := echoServiceDesc
+	desc.Methods = []grpc.MethodDesc{{
+		MethodName: "Create",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := namedMessage{dynamicpb.NewMessage(echoFile.Messages().ByName("Message"))}
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/zeus.test.Echo/Create"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return req, nil
+			})
+		},
+	}}
+	srv.RegisterService(&desc, echoService{})
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		code   int
+		fields map[string]string
+	}{
+		{http.MethodGet, "/health", "", 200, nil},
+		{http.MethodPost, "/signup", `{"name":"zeus","age":20,"address":{"city":"Athens"}}`, 200, nil},
+		{http.MethodPost, "/signup", `{"name":"zeus","age":10,"address":{"city":"Athens"}}`, 400, map[string]string{"age": "gte=18"}},
+		{http.MethodPost, "/v1/messages", `{"id":"1","name":"zeus"}`, 200, nil},
+		{http.MethodPost, "/v1/messages", `{"id":"1"}`, 400, map[string]string{"name": "value is required"}},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		req.Header.Set("Content-Type", appJSONStr)
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, req)
+		if res.Code != test.code {
+			t.Errorf("%s %s: expected %d got %d %s", test.path, test.body, test.code, res.Code, res.Body.String())
+		}
+		if test.fields == nil {
+			continue
+		}
+		var status struct {
+			Reason   string            `json:"reason"`
+			Metadata map[string]string `json:"metadata"`
+		}
+		if err := json.Unmarshal(res.Body.Bytes(), &status); err != nil {
+			t.Fatal(err)
+		}
+		if status.Reason != reasonValidator || !reflect.DeepEqual(status.Metadata, test.fields) {
+			t.Errorf("%s %s: expected %s %v got %s %v", test.path, test.body, reasonValidator, test.fields, status.Reason, status.Metadata)
+		}
+	}
+}
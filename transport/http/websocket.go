@@ -0,0 +1,292 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultWSReadLimit    = 1 << 20
+	defaultWSPingInterval = 30 * time.Second
+	defaultWSPongWait     = 60 * time.Second
+	defaultWSWriteTimeout = 10 * time.Second
+
+	// maxCloseReason is the maximum length of the reason of a close frame.
+	maxCloseReason = 123
+)
+
+// WSHandlerFunc defines a function to serve websocket connections.
+type WSHandlerFunc func(Context, *Conn) error
+
+// WSOption is a websocket option.
+type WSOption func(*wsOptions)
+
+type wsOptions struct {
+	readLimit    int64
+	pingInterval time.Duration
+	pongWait     time.Duration
+	writeTimeout time.Duration
+	checkOrigin  func(*http.Request) bool
+	subprotocols []string
+}
+
+// WSReadLimit with the maximum size of a message read from the peer, default 1MB.
+func WSReadLimit(n int64) WSOption {
+	return func(o *wsOptions) {
+		o.readLimit = n
+	}
+}
+
+// WSPingInterval with the interval of the pings sent to the peer, default 30s.
+func WSPingInterval(d time.Duration) WSOption {
+	return func(o *wsOptions) {
+		o.pingInterval = d
+	}
+}
+
+// WSPongWait with the time allowed to read the next pong or message from the peer, default 60s.
+func WSPongWait(d time.Duration) WSOption {
+	return func(o *wsOptions) {
+		o.pongWait = d
+	}
+}
+
+// WSWriteTimeout with the time allowed to write a message to the peer, default 10s.
+func WSWriteTimeout(d time.Duration) WSOption {
+	return func(o *wsOptions) {
+		o.writeTimeout = d
+	}
+}
+
+// WSCheckOrigin with the origin check of the upgrade requests, by default
+// the origin must match the host of the request.
+func WSCheckOrigin(fn func(*http.Request) bool) WSOption {
+	return func(o *wsOptions) {
+		o.checkOrigin = fn
+	}
+}
+
+// WSSubprotocols with the supported subprotocols in order of preference, default "json" and "proto".
+// A subprotocol naming a registered codec selects the codec of the messages.
+func WSSubprotocols(protocols ...string) WSOption {
+	return func(o *wsOptions) {
+		o.subprotocols = protocols
+	}
+}
+
+// WebSocket with the websocket options of the server.
+func WebSocket(opts ...WSOption) ServerOption {
+	return func(s *Server) {
+		for _, o := range opts {
+			o(&s.ws)
+		}
+	}
+}
+
+func defaultWSOptions() wsOptions {
+	return wsOptions{
+		readLimit:    defaultWSReadLimit,
+		pingInterval: defaultWSPingInterval,
+		pongWait:     defaultWSPongWait,
+		writeTimeout: defaultWSWriteTimeout,
+		subprotocols: []string{"json", "proto"},
+	}
+}
+
+// WS registers a new websocket route for a path with matching handler in the router.
+// The upgrade happens after the middleware has run, so a request rejected by the
+// middleware gets a regular HTTP error response. Once upgraded, the error returned
+// by the handler closes the connection with its reason. The route is not bounded by
+// the server timeout, the connection lasts until it is closed.
+func (r *Router) WS(path string, h WSHandlerFunc, m ...middleware.Middleware) {
+	r.handle(http.MethodGet, path, func(ctx Context) error {
+		if !ctx.IsWebsocket() {
+			return errors.BadRequest("WEBSOCKET_UPGRADE_REQUIRED", "websocket upgrade required")
+		}
+		conn, err := r.srv.upgrade(ctx.Response(), ctx.Request())
+		if err != nil {
+			// the upgrader has replied with the error.
+			return nil
+		}
+		defer conn.Close()
+		if err := h(ctx, conn); err != nil {
+			conn.closeWithError(err)
+		}
+		return nil
+	}, true, m...)
+}
+
+// Conn is a websocket connection, whose messages are encoded by the codec
+// negotiated through the subprotocol, JSON by default.
+type Conn struct {
+	ws     *websocket.Conn
+	codec  encoding.Codec
+	opts   wsOptions
+	ctx    context.Context
+	cancel context.CancelFunc
+	srv    *Server
+
+	wmu  sync.Mutex
+	once sync.Once
+	done chan struct{}
+}
+
+func (s *Server) upgrade(w http.ResponseWriter, req *http.Request) (*Conn, error) {
+	upgrader := websocket.Upgrader{
+		CheckOrigin:  s.ws.checkOrigin,
+		Subprotocols: s.ws.subprotocols,
+	}
+	ws, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return nil, err
+	}
+	codec := encoding.GetCodec(ws.Subprotocol())
+	if codec == nil {
+		codec, _ = CodecForRequest(req, "Content-Type")
+	}
+	ctx, cancel := context.WithCancel(req.Context())
+	c := &Conn{
+		ws:     ws,
+		codec:  codec,
+		opts:   s.ws,
+		ctx:    ctx,
+		cancel: cancel,
+		srv:    s,
+		done:   make(chan struct{}),
+	}
+	ws.SetReadLimit(s.ws.readLimit)
+	_ = ws.SetReadDeadline(time.Now().Add(s.ws.pongWait))
+	ws.SetPongHandler(func(string) error {
+		return ws.SetReadDeadline(time.Now().Add(s.ws.pongWait))
+	})
+	s.wsMu.Lock()
+	s.wsConns[c] = struct{}{}
+	s.wsMu.Unlock()
+	go c.ping()
+	return c, nil
+}
+
+// Context returns the context of the connection, it is canceled once the connection is closed.
+func (c *Conn) Context() context.Context {
+	return c.ctx
+}
+
+// Subprotocol returns the negotiated subprotocol.
+func (c *Conn) Subprotocol() string {
+	return c.ws.Subprotocol()
+}
+
+// ReadMessage reads the next message and decodes it into v,
+// it returns io.EOF once the peer has closed the connection.
+func (c *Conn) ReadMessage(v interface{}) error {
+	_, data, err := c.ws.ReadMessage()
+	if err != nil {
+		select {
+		case <-c.done:
+			// closed by the handler or the server shutdown.
+			return io.EOF
+		default:
+		}
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+			return io.EOF
+		}
+		return err
+	}
+	return c.codec.Unmarshal(data, v)
+}
+
+// WriteMessage encodes v and writes it as a message, a text message with the
+// JSON codec and a binary message otherwise. It is safe for concurrent use.
+func (c *Conn) WriteMessage(v interface{}) error {
+	data, err := c.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	messageType := websocket.BinaryMessage
+	if c.codec.Name() == "json" {
+		messageType = websocket.TextMessage
+	}
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	_ = c.ws.SetWriteDeadline(time.Now().Add(c.opts.writeTimeout))
+	return c.ws.WriteMessage(messageType, data)
+}
+
+// Raw returns the underlying websocket connection.
+func (c *Conn) Raw() *websocket.Conn {
+	return c.ws
+}
+
+// Close sends a normal closure to the peer and closes the connection.
+func (c *Conn) Close() error {
+	return c.close(websocket.CloseNormalClosure, "")
+}
+
+func (c *Conn) closeWithError(err error) {
+	var (
+		se     = errors.FromError(err)
+		code   = websocket.CloseInternalServerErr
+		reason = se.Reason
+	)
+	if errors.Is(err, websocket.ErrReadLimit) {
+		code = websocket.CloseMessageTooBig
+	}
+	if len(reason) > maxCloseReason {
+		reason = reason[:maxCloseReason]
+	}
+	_ = c.close(code, reason)
+}
+
+func (c *Conn) close(code int, reason string) error {
+	var err error
+	c.once.Do(func() {
+		close(c.done)
+		c.cancel()
+		_ = c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(c.opts.writeTimeout))
+		err = c.ws.Close()
+		c.srv.wsMu.Lock()
+		delete(c.srv.wsConns, c)
+		c.srv.wsMu.Unlock()
+	})
+	return err
+}
+
+func (c *Conn) ping() {
+	if c.opts.pingInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(c.opts.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.opts.writeTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// closeWebSockets closes the websocket connections when the server shuts down,
+// the peers are told the server is going away.
+func (s *Server) closeWebSockets() {
+	s.wsMu.Lock()
+	conns := make([]*Conn, 0, len(s.wsConns))
+	for c := range s.wsConns {
+		conns = append(conns, c)
+	}
+	s.wsMu.Unlock()
+	for _, c := range conns {
+		_ = c.close(websocket.CloseGoingAway, "server shutting down")
+	}
+}
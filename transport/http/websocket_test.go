@@ -0,0 +1,234 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/JellyTony/zeus/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/proto"
+)
+
+func newWSServer(t *testing.T, opts ...ServerOption) (*Server, *int32) {
+	t.Helper()
+	var calls int32
+	srv := NewServer(append([]ServerOption{Timeout(50 * time.Millisecond)}, opts...)...)
+	srv.Use("/ws/*", func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, _ := transport.FromServerContext(ctx)
+			if tr.RequestHeader().Get("Authorization") == "" {
+				return nil, errors.Unauthorized("UNAUTHORIZED", "token is required")
+			}
+			atomic.AddInt32(&calls, 1)
+			return handler(ctx, req)
+		}
+	})
+	r := srv.Route("/ws")
+	r.WS("/echo", func(ctx Context, conn *Conn) error {
+		for {
+			var v map[string]interface{}
+			if conn.Subprotocol() == "proto" {
+				reply := new(helloworld.HelloReply)
+				if err := conn.ReadMessage(reply); err != nil {
+					return ignoreEOF(err)
+				}
+				if err := conn.WriteMessage(reply); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := conn.ReadMessage(&v); err != nil {
+				return ignoreEOF(err)
+			}
+			if err := conn.WriteMessage(v); err != nil {
+				return err
+			}
+		}
+	})
+	r.WS("/fail", func(ctx Context, conn *Conn) error {
+		return errors.Forbidden("CHANNEL_FORBIDDEN", "channel is forbidden")
+	})
+	return srv, &calls
+}
+
+func ignoreEOF(err error) error {
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+func dialWS(t *testing.T, url string, protocols ...string) (*websocket.Conn, *http.Response, error) {
+	t.Helper()
+	dialer := websocket.Dialer{Subprotocols: protocols, HandshakeTimeout: time.Second}
+	return dialer.Dial("ws"+strings.TrimPrefix(url, "http"), http.Header{"Authorization": {"token"}})
+}
+
+func TestWebSocket(t *testing.T) {
+	srv, calls := newWSServer(t)
+	ts := newTestServer(t, srv)
+
+	conn, _, err := dialWS(t, ts.URL+"/ws/echo", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// the connection outlives the server timeout.
+	time.Sleep(100 * time.Millisecond)
+	if err = conn.WriteMessage(websocket.TextMessage, []byte(`{"name":"zeus"}`)); err != nil {
+		t.Fatal(err)
+	}
+	typ, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ != websocket.TextMessage || string(data) != `{"name":"zeus"}` {
+		t.Errorf("unexpected message %d %s", typ, data)
+	}
+	if n := atomic.LoadInt32(calls); n != 1 {
+		t.Errorf("expected %d middleware calls got %d", 1, n)
+	}
+
+	conn, _, err = dialWS(t, ts.URL+"/ws/echo", "proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	data, _ = proto.Marshal(&helloworld.HelloReply{Message: "hello"})
+	if err = conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+		t.Fatal(err)
+	}
+	typ, data, err = conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply := new(helloworld.HelloReply)
+	if err = proto.Unmarshal(data, reply); err != nil {
+		t.Fatal(err)
+	}
+	if typ != websocket.BinaryMessage || reply.Message != "hello" {
+		t.Errorf("unexpected message %d %v", typ, reply)
+	}
+}
+
+func TestWebSocketRejected(t *testing.T) {
+	srv, _ := newWSServer(t)
+	ts := newTestServer(t, srv)
+
+	_, resp, err := (&websocket.Dialer{}).Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws/echo", nil)
+	if err == nil {
+		t.Fatal("expected the upgrade to be rejected")
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected %d got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/ws/echo", nil)
+	req.Header.Set("Authorization", "token")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected %d got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestWebSocketClose(t *testing.T) {
+	srv, _ := newWSServer(t, WebSocket(WSReadLimit(16)))
+	ts := newTestServer(t, srv)
+
+	tests := []struct {
+		path    string
+		message string
+		code    int
+		reason  string
+	}{
+		{"/ws/fail", "", websocket.CloseInternalServerErr, "CHANNEL_FORBIDDEN"},
+		{"/ws/echo", `{"name":"a message over the limit"}`, websocket.CloseMessageTooBig, ""},
+	}
+	for _, test := range tests {
+		conn, _, err := dialWS(t, ts.URL+test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.message != "" {
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(test.message))
+		}
+		_, _, err = conn.ReadMessage()
+		ce, ok := err.(*websocket.CloseError)
+		if !ok {
+			t.Fatalf("%s: expected close error got %v", test.path, err)
+		}
+		if ce.Code != test.code || (test.reason != "" && ce.Text != test.reason) {
+			t.Errorf("%s: expected %d %s got %d %s", test.path, test.code, test.reason, ce.Code, ce.Text)
+		}
+		conn.Close()
+	}
+}
+
+func TestWebSocketPing(t *testing.T) {
+	srv, _ := newWSServer(t, WebSocket(WSPingInterval(10*time.Millisecond)))
+	ts := newTestServer(t, srv)
+
+	conn, _, err := dialWS(t, ts.URL+"/ws/echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var pings int32
+	conn.SetPingHandler(func(data string) error {
+		atomic.AddInt32(&pings, 1)
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+	_ = conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	_, _, _ = conn.ReadMessage()
+	if atomic.LoadInt32(&pings) == 0 {
+		t.Error("expected pings from the server")
+	}
+}
+
+func TestWebSocketShutdown(t *testing.T) {
+	srv, _ := newWSServer(t, Address("127.0.0.1:0"))
+	e := startTestServer(t, srv)
+	conn, _, err := dialWS(t, e.String()+"/ws/echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err = srv.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Errorf("expected going away close got %v", err)
+	}
+}
+
+func TestWebSocketSpoofedUpgrade(t *testing.T) {
+	srv := NewServer()
+	srv.Route("/").GET("/users", func(ctx Context) error {
+		_, ok := ctx.Request().Context().Deadline()
+		return ctx.String(200, strconv.FormatBool(ok))
+	})
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, req)
+	// the upgrade headers do not exempt a regular route from the timeout.
+	if got := res.Body.String(); got != "true" {
+		t.Errorf("expected deadline %s got %s", "true", got)
+	}
+}